@@ -0,0 +1,74 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package goinproc validates the Go TiKV client by calling it in-process. It
+// registers the "go-inproc" client, which is selected by `-client go-inproc`.
+package goinproc
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/pkg/errors"
+	"github.com/tikv/client-validator/stub"
+)
+
+// Name is the name of the client registered by the package.
+const Name = "go-inproc"
+
+const clientModule = "github.com/tikv/client-go/v2"
+
+func init() {
+	stub.RegisterDriver(Name, driver{})
+}
+
+type driver struct{}
+
+func (driver) NewRawKV(pdAddrs []string) (stub.RawKV, error) {
+	return newRawClient(pdAddrs)
+}
+
+func (driver) NewTxnKV(pdAddrs []string) (stub.TxnKV, error) {
+	return newTxnClient(pdAddrs)
+}
+
+// GetClientInfo reports the version of the Go client linked into the binary.
+func (driver) GetClientInfo() (*stub.ClientInfo, error) {
+	info := &stub.ClientInfo{
+		Name:     "client-go",
+		Language: "go",
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range bi.Deps {
+			if dep.Path == clientModule {
+				info.Version = dep.Version
+			}
+		}
+	}
+	return info, nil
+}
+
+// newContext bounds each call to the client by stub.CallTimeout, as the
+// transports do for calls to a proxy server.
+func newContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), stub.CallTimeout)
+}
+
+// callErr marks err as a timeout of the harness if the call ran out of the
+// deadline of ctx, tests tell it from errors returned by the client.
+func callErr(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return errors.Wrap(stub.ErrCallTimeout, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,159 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goinproc
+
+import (
+	"github.com/tikv/client-go/v2/rawkv"
+	"github.com/tikv/client-validator/stub"
+)
+
+// rawClient implements stub.RawKV by a rawkv.Client.
+type rawClient struct {
+	client *rawkv.Client
+	cf     string
+	view   bool // returned by WithCF, it does not own the client
+}
+
+var _ stub.RawKV = (*rawClient)(nil)
+
+func newRawClient(pdAddrs []string) (*rawClient, error) {
+	ctx, cancel := newContext()
+	defer cancel()
+	client, err := rawkv.NewClientWithOpts(ctx, pdAddrs)
+	if err != nil {
+		return nil, callErr(ctx, err)
+	}
+	return &rawClient{client: client}, nil
+}
+
+func (c *rawClient) Close() error {
+	if c.view {
+		return nil
+	}
+	return c.client.Close()
+}
+
+func (c *rawClient) WithCF(cf string) stub.RawKV {
+	return &rawClient{client: c.client, cf: cf, view: true}
+}
+
+func (c *rawClient) Get(key []byte) ([]byte, error) {
+	ctx, cancel := newContext()
+	defer cancel()
+	v, err := c.client.Get(ctx, key, c.options()...)
+	return v, callErr(ctx, err)
+}
+
+func (c *rawClient) BatchGet(keys [][]byte) ([][]byte, error) {
+	ctx, cancel := newContext()
+	defer cancel()
+	v, err := c.client.BatchGet(ctx, keys, c.options()...)
+	return v, callErr(ctx, err)
+}
+
+func (c *rawClient) Put(key, value []byte) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	return callErr(ctx, c.client.Put(ctx, key, value, c.options()...))
+}
+
+func (c *rawClient) BatchPut(keys, values [][]byte) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	return callErr(ctx, c.client.BatchPut(ctx, keys, values, c.options()...))
+}
+
+func (c *rawClient) PutWithTTL(key, value []byte, ttl uint64) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	return callErr(ctx, c.client.PutWithTTL(ctx, key, value, ttl, c.options()...))
+}
+
+func (c *rawClient) BatchPutWithTTL(keys, values [][]byte, ttls []uint64) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	return callErr(ctx, c.client.BatchPutWithTTL(ctx, keys, values, ttls, c.options()...))
+}
+
+func (c *rawClient) GetKeyTTL(key []byte) (*uint64, error) {
+	ctx, cancel := newContext()
+	defer cancel()
+	v, err := c.client.GetKeyTTL(ctx, key, c.options()...)
+	return v, callErr(ctx, err)
+}
+
+func (c *rawClient) SetAtomicForCAS(atomic bool) error {
+	c.client.SetAtomicForCAS(atomic)
+	return nil
+}
+
+func (c *rawClient) CompareAndSwap(key, previousValue, newValue []byte, previousNotExist bool) ([]byte, bool, error) {
+	// The Go client tells a missing previous value by nil.
+	switch {
+	case previousNotExist:
+		previousValue = nil
+	case previousValue == nil:
+		previousValue = []byte{}
+	}
+	ctx, cancel := newContext()
+	defer cancel()
+	v, ok, err := c.client.CompareAndSwap(ctx, key, previousValue, newValue, c.options()...)
+	return v, ok, callErr(ctx, err)
+}
+
+func (c *rawClient) Delete(key []byte) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	return callErr(ctx, c.client.Delete(ctx, key, c.options()...))
+}
+
+func (c *rawClient) BatchDelete(keys [][]byte) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	return callErr(ctx, c.client.BatchDelete(ctx, keys, c.options()...))
+}
+
+func (c *rawClient) DeleteRange(startKey, endKey []byte) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	return callErr(ctx, c.client.DeleteRange(ctx, startKey, endKey, c.options()...))
+}
+
+func (c *rawClient) Scan(startKey, endKey []byte, limit int) ([][]byte, [][]byte, error) {
+	ctx, cancel := newContext()
+	defer cancel()
+	keys, values, err := c.client.Scan(ctx, startKey, endKey, limit, c.options()...)
+	return keys, values, callErr(ctx, err)
+}
+
+func (c *rawClient) ReverseScan(startKey, endKey []byte, limit int) ([][]byte, [][]byte, error) {
+	ctx, cancel := newContext()
+	defer cancel()
+	keys, values, err := c.client.ReverseScan(ctx, startKey, endKey, limit, c.options()...)
+	return keys, values, callErr(ctx, err)
+}
+
+func (c *rawClient) ScanKeyOnly(startKey, endKey []byte, limit int) ([][]byte, [][]byte, error) {
+	ctx, cancel := newContext()
+	defer cancel()
+	keys, values, err := c.client.Scan(ctx, startKey, endKey, limit, append(c.options(), rawkv.ScanKeyOnly())...)
+	return keys, values, callErr(ctx, err)
+}
+
+func (c *rawClient) options() []rawkv.RawOption {
+	if c.cf == "" {
+		return nil
+	}
+	return []rawkv.RawOption{rawkv.SetColumnFamily(c.cf)}
+}
@@ -0,0 +1,253 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goinproc
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	tikverr "github.com/tikv/client-go/v2/error"
+	"github.com/tikv/client-go/v2/kv"
+	"github.com/tikv/client-go/v2/tikv"
+	"github.com/tikv/client-go/v2/txnkv"
+	"github.com/tikv/client-go/v2/txnkv/transaction"
+	"github.com/tikv/client-validator/stub"
+)
+
+// txnClient implements stub.TxnKV by a txnkv.Client.
+type txnClient struct {
+	client *txnkv.Client
+}
+
+var _ stub.TxnKV = (*txnClient)(nil)
+
+func newTxnClient(pdAddrs []string) (*txnClient, error) {
+	client, err := txnkv.NewClient(pdAddrs)
+	if err != nil {
+		return nil, err
+	}
+	return &txnClient{client: client}, nil
+}
+
+func (c *txnClient) Close() error {
+	return c.client.Close()
+}
+
+func (c *txnClient) Begin() (stub.Transaction, error) {
+	return c.BeginWithOptions(stub.TxnOptions{})
+}
+
+func (c *txnClient) BeginPessimistic() (stub.Transaction, error) {
+	return c.BeginWithOptions(stub.TxnOptions{Pessimistic: true})
+}
+
+func (c *txnClient) BeginWithOptions(opts stub.TxnOptions) (stub.Transaction, error) {
+	txn, err := c.client.Begin()
+	if err != nil {
+		return nil, err
+	}
+	txn.SetPessimistic(opts.Pessimistic)
+	txn.SetEnableAsyncCommit(opts.AsyncCommit)
+	txn.SetEnable1PC(opts.OnePC)
+	if opts.EntrySizeLimit > 0 || opts.TotalSizeLimit > 0 {
+		// The Go client does not limit the membuffer by default.
+		entryLimit, totalLimit := opts.EntrySizeLimit, opts.TotalSizeLimit
+		if entryLimit == 0 {
+			entryLimit = math.MaxUint64
+		}
+		if totalLimit == 0 {
+			totalLimit = math.MaxUint64
+		}
+		txn.GetUnionStore().SetEntrySizeLimit(entryLimit, totalLimit)
+	}
+	return &transactionAdapter{txn: txn}, nil
+}
+
+func (c *txnClient) BeginWithTS(ts uint64) (stub.Transaction, error) {
+	txn, err := c.client.Begin(tikv.WithStartTS(ts))
+	if err != nil {
+		return nil, err
+	}
+	return &transactionAdapter{txn: txn}, nil
+}
+
+func (c *txnClient) GetTS() (uint64, error) {
+	ctx, cancel := newContext()
+	defer cancel()
+	ts, err := c.client.GetTimestamp(ctx)
+	return ts, callErr(ctx, err)
+}
+
+// transactionAdapter implements stub.Transaction by a transaction.KVTxn.
+type transactionAdapter struct {
+	txn             *transaction.KVTxn
+	lockWaitTimeout time.Duration
+}
+
+var _ stub.Transaction = (*transactionAdapter)(nil)
+
+func (t *transactionAdapter) Get(k []byte) ([]byte, error) {
+	ctx, cancel := newContext()
+	defer cancel()
+	v, err := t.txn.Get(ctx, k)
+	// A missing key is not an error for stubs.
+	if tikverr.IsErrNotFound(err) {
+		return nil, nil
+	}
+	return v, callErr(ctx, err)
+}
+
+func (t *transactionAdapter) BatchGet(keys [][]byte) (map[string][]byte, error) {
+	ctx, cancel := newContext()
+	defer cancel()
+	m, err := t.txn.BatchGet(ctx, keys)
+	return m, callErr(ctx, err)
+}
+
+func (t *transactionAdapter) Set(k []byte, v []byte) error {
+	return t.txn.Set(k, v)
+}
+
+func (t *transactionAdapter) Delete(k []byte) error {
+	return t.txn.Delete(k)
+}
+
+func (t *transactionAdapter) Iter(k []byte, upperBound []byte) (stub.Iterator, error) {
+	if !t.txn.Valid() {
+		return nil, errors.New("transaction is not valid")
+	}
+	iter, err := t.txn.Iter(k, upperBound)
+	if err != nil {
+		return nil, err
+	}
+	return &iteratorAdapter{txn: t.txn, iter: iter}, nil
+}
+
+func (t *transactionAdapter) IterReverse(k []byte) (stub.Iterator, error) {
+	if !t.txn.Valid() {
+		return nil, errors.New("transaction is not valid")
+	}
+	iter, err := t.txn.IterReverse(k)
+	if err != nil {
+		return nil, err
+	}
+	return &iteratorAdapter{txn: t.txn, iter: iter}, nil
+}
+
+func (t *transactionAdapter) IsReadOnly() (bool, error) {
+	return t.txn.IsReadOnly(), nil
+}
+
+func (t *transactionAdapter) Commit() error {
+	ctx, cancel := newContext()
+	defer cancel()
+	return callErr(ctx, t.txn.Commit(ctx))
+}
+
+func (t *transactionAdapter) Rollback() error {
+	return t.txn.Rollback()
+}
+
+func (t *transactionAdapter) SetLockWaitTimeout(timeout time.Duration) {
+	t.lockWaitTimeout = timeout
+}
+
+func (t *transactionAdapter) LockKeys(keys ...[]byte) error {
+	lockWaitTime := kv.LockAlwaysWait
+	switch {
+	case t.lockWaitTimeout < 0:
+		lockWaitTime = kv.LockNoWait
+	case t.lockWaitTimeout > 0:
+		lockWaitTime = int64(t.lockWaitTimeout / time.Millisecond)
+	}
+	ctx, cancel := newContext()
+	defer cancel()
+	return callErr(ctx, t.txn.LockKeysWithWaitTime(ctx, lockWaitTime, keys...))
+}
+
+func (t *transactionAdapter) Valid() (bool, error) {
+	return t.txn.Valid(), nil
+}
+
+func (t *transactionAdapter) Len() (int, error) {
+	return t.txn.Len(), nil
+}
+
+func (t *transactionAdapter) Size() (int, error) {
+	return t.txn.Size(), nil
+}
+
+// iteratorAdapter implements stub.Iterator by an iterator of a KVTxn. The Go
+// client does not check the iterator after the transaction is finished, the
+// adapter does it as a proxy server would.
+type iteratorAdapter struct {
+	txn  interface{ Valid() bool }
+	iter interface {
+		Valid() bool
+		Key() []byte
+		Value() []byte
+		Next() error
+		Close()
+	}
+	closed bool
+}
+
+var _ stub.Iterator = (*iteratorAdapter)(nil)
+
+func (it *iteratorAdapter) Valid() (bool, error) {
+	if err := it.check(); err != nil {
+		return false, err
+	}
+	return it.iter.Valid(), nil
+}
+
+func (it *iteratorAdapter) Key() ([]byte, error) {
+	if err := it.check(); err != nil {
+		return nil, err
+	}
+	return it.iter.Key(), nil
+}
+
+func (it *iteratorAdapter) Value() ([]byte, error) {
+	if err := it.check(); err != nil {
+		return nil, err
+	}
+	return it.iter.Value(), nil
+}
+
+func (it *iteratorAdapter) Next() error {
+	if err := it.check(); err != nil {
+		return err
+	}
+	return it.iter.Next()
+}
+
+func (it *iteratorAdapter) Close() error {
+	if !it.closed {
+		it.closed = true
+		it.iter.Close()
+	}
+	return nil
+}
+
+func (it *iteratorAdapter) check() error {
+	if it.closed {
+		return errors.New("iterator is closed")
+	}
+	if !it.txn.Valid() {
+		return errors.New("transaction is not valid")
+	}
+	return nil
+}
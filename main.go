@@ -17,9 +17,17 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/logrusorgru/aurora"
+	"github.com/pkg/errors"
+	_ "github.com/tikv/client-validator/adapter/goinproc" // register in-process clients
+	"github.com/tikv/client-validator/stub"
 	_ "github.com/tikv/client-validator/tests" // import tests
 	"github.com/tikv/client-validator/validator"
 )
@@ -28,15 +36,286 @@ var (
 	showRecord  = flag.String("record", "failed", "none | failed | all")
 	showLog     = flag.Bool("show-log", false, "show test logs in report")
 	outputStyle = flag.String("output", "console", "console | text | json")
+	seed        = flag.Int64("seed", 0, "seed of randomized workloads, 0 means a random seed")
+	bench       = flag.String("bench", "", "run benchmarks matching the regular expression, e.g. . for all")
+	benchTime   = flag.String("benchtime", "1s", "run each round of benchmarks for the duration or count of operations, e.g. 10s or 1000x")
+	benchWarmup = flag.Duration("bench-warmup", time.Second, "run benchmarks for the duration before measuring")
+	benchCount  = flag.Int("bench-count", 1, "run each benchmark for the count of rounds, compare-bench needs 4 or more rounds to find significant changes")
+	soak        = flag.String("soak", "", "run soaks matching the regular expression, e.g. . for all")
+	soakTime    = flag.Duration("soak-duration", time.Hour, "run each soak for the duration")
+	soakWindow  = flag.Duration("soak-window", time.Minute, "length of windows in the timeline of soaks")
+	alpha       = flag.Float64("alpha", 0.05, "significance level of compare-bench")
+	threshold   = flag.Float64("threshold", 0.05, "relative change of a benchmark metric to be a regression for compare-bench")
 )
 
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [replay <report.json> | compare-bench <old.json> <new.json>]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
-	report := validator.RunAll()
+	if flag.Arg(0) == "compare-bench" {
+		os.Exit(compareBench())
+	}
+	opts, err := runOptions()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	report := validator.Run(opts)
+	fillManifest(report.Manifest)
+	if report.Manifest.Capabilities != nil {
+		report.CheckCapabilities(report.Manifest.Capabilities)
+	}
 	trimReport(&report)
 	printReport(&report)
 }
 
+func runOptions() (validator.Options, error) {
+	switch flag.Arg(0) {
+	case "":
+		opts := validator.Options{Seed: *seed}
+		if opts.Seed == 0 {
+			opts.Seed = time.Now().UnixNano()
+		}
+		if err := benchOptions(&opts); err != nil {
+			return opts, err
+		}
+		return opts, soakOptions(&opts)
+	case "replay":
+		if flag.NArg() != 2 {
+			return validator.Options{}, errors.New("usage: replay <report.json>")
+		}
+		return replayOptions(flag.Arg(1))
+	default:
+		return validator.Options{}, errors.Errorf("unknown command: %s", flag.Arg(0))
+	}
+}
+
+// benchOptions fills options of benchmarks by flags.
+func benchOptions(opts *validator.Options) error {
+	if *bench == "" {
+		return nil
+	}
+	re, err := regexp.Compile(*bench)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if opts.BenchTime, err = validator.ParseBenchTime(*benchTime); err != nil {
+		return err
+	}
+	opts.Bench = re
+	opts.BenchWarmup = *benchWarmup
+	opts.BenchCount = *benchCount
+	return nil
+}
+
+// soakOptions fills options of soaks by flags.
+func soakOptions(opts *validator.Options) error {
+	if *soak == "" {
+		return nil
+	}
+	re, err := regexp.Compile(*soak)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	opts.Soak = re
+	opts.SoakDuration = *soakTime
+	opts.SoakWindow = *soakWindow
+	// Goroutines of a client behind a proxy server are not in the process.
+	opts.SoakCheckGoroutines = flag.Lookup("client").Value.String() != stub.ClientProxy
+	return nil
+}
+
+// replayOptions loads a json report and returns options to re-run the same
+// features, tests, benchmarks and soaks with the same seed and flags.
+func replayOptions(path string) (validator.Options, error) {
+	report, err := loadReport(path)
+	if err != nil {
+		return validator.Options{}, err
+	}
+	if report.Manifest == nil {
+		return validator.Options{}, errors.Errorf("report %s does not contain a manifest", path)
+	}
+	if err = replayFlags(report.Manifest.Flags); err != nil {
+		return validator.Options{}, errors.WithMessagef(err, "replay %s", path)
+	}
+	opts := validator.Options{
+		Seed:     report.Manifest.Seed,
+		Features: report.Manifest.Features,
+		Tests:    report.Manifest.Tests,
+	}
+	*bench = keysPattern(report.Manifest.Benchmarks)
+	*soak = keysPattern(report.Manifest.Soaks)
+	if err = benchOptions(&opts); err != nil {
+		return validator.Options{}, err
+	}
+	if err = soakOptions(&opts); err != nil {
+		return validator.Options{}, err
+	}
+	if err = opts.Validate(); err != nil {
+		return validator.Options{}, errors.WithMessagef(err, "replay %s", path)
+	}
+	return opts, nil
+}
+
+// outputFlags only change how the report is printed, they are not replayed.
+var outputFlags = map[string]bool{"record": true, "show-log": true, "output": true}
+
+// replayFlags sets flags recorded in the manifest of a report, e.g. the client
+// and size limits, unless they are given in the command line.
+func replayFlags(recorded map[string]string) error {
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	for name, value := range recorded {
+		if explicit[name] || outputFlags[name] || flag.Lookup(name) == nil {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return errors.Wrapf(err, "flag %s", name)
+		}
+	}
+	return nil
+}
+
+// keysPattern returns a regular expression matching exactly the keys, or an
+// empty string if there is no key.
+func keysPattern(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = regexp.QuoteMeta(key)
+	}
+	return "^(" + strings.Join(quoted, "|") + ")$"
+}
+
+func loadReport(path string) (*validator.Report, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var report validator.Report
+	if err = json.Unmarshal(data, &report); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &report, nil
+}
+
+// compareBench compares benchmarks of two json reports and prints the changes.
+// It returns the exit code, which is 1 if any regression is found, including
+// benchmarks without results in the new report, or 2 if benchmarks have too
+// few rounds to be compared.
+func compareBench() int {
+	if flag.NArg() != 3 {
+		fmt.Fprintln(os.Stderr, "usage: compare-bench <old.json> <new.json>")
+		return 2
+	}
+	var reports [2]*validator.Report
+	for i := range reports {
+		report, err := loadReport(flag.Arg(i + 1))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		reports[i] = report
+	}
+	comparisons := validator.CompareBenchmarks(reports[0].Benchmarks, reports[1].Benchmarks, validator.CompareOptions{
+		Alpha:     *alpha,
+		Threshold: *threshold,
+	})
+	if *outputStyle == "json" {
+		data, _ := json.MarshalIndent(comparisons, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		printComparisons(comparisons)
+	}
+	// A regression can not be found with too few rounds, so the comparison
+	// fails instead of passing silently.
+	var fewSamples []string
+	for _, c := range comparisons {
+		if c.Metric == validator.MetricThroughput && (c.OldSamples < validator.MinCompareSamples || c.NewSamples < validator.MinCompareSamples) {
+			fewSamples = append(fewSamples, fmt.Sprintf("%s (n=%d+%d)", c.Key, c.OldSamples, c.NewSamples))
+		}
+	}
+	for _, c := range comparisons {
+		if c.Regression {
+			return 1
+		}
+	}
+	if len(fewSamples) > 0 {
+		fmt.Fprintf(os.Stderr, "too few rounds to find regressions, run benchmarks with -bench-count=%d or more: %s\n",
+			validator.MinCompareSamples, strings.Join(fewSamples, ", "))
+		return 2
+	}
+	return 0
+}
+
+func printComparisons(comparisons []validator.BenchComparison) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "benchmark\tmetric\told\tnew\tdelta\t")
+	for _, c := range comparisons {
+		delta := "~"
+		switch {
+		case c.Missing:
+			delta = "missing"
+		case c.Significant:
+			delta = fmt.Sprintf("%+.2f%%", c.Delta*100)
+		}
+		mark := ""
+		if c.Regression {
+			mark = "REGRESSION"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s (p=%.3f n=%d+%d)\t%s\n", c.Key, c.Metric,
+			formatMetric(c.Metric, c.Old), formatMetric(c.Metric, c.New), delta, c.P, c.OldSamples, c.NewSamples, mark)
+	}
+	w.Flush()
+}
+
+func formatMetric(metric string, v float64) string {
+	if metric == validator.MetricThroughput {
+		return fmt.Sprintf("%.2f/s", v)
+	}
+	return time.Duration(v).String()
+}
+
+func fillManifest(manifest *validator.Manifest) {
+	manifest.Flags = make(map[string]string)
+	flag.VisitAll(func(f *flag.Flag) {
+		manifest.Flags[f.Name] = f.Value.String()
+	})
+	manifest.ProxyAddr = manifest.Flags["client-proxy"]
+	manifest.MockTiKVAddr = manifest.Flags["mock-tikv"]
+	// The proxy server may not report its identity, leave it empty in this case.
+	if info, err := clientInfo(manifest); err == nil {
+		manifest.ClientName = info.Name
+		manifest.ClientLanguage = info.Language
+		manifest.ClientVersion = info.Version
+		manifest.ClientGitCommit = info.GitCommit
+		manifest.Capabilities = info.Capabilities
+	}
+}
+
+// clientInfo queries the identity of the client selected by the client flag.
+func clientInfo(manifest *validator.Manifest) (*stub.ClientInfo, error) {
+	if name := manifest.Flags["client"]; name != stub.ClientProxy {
+		driver, err := stub.GetDriver(name)
+		if err != nil {
+			return nil, err
+		}
+		return driver.GetClientInfo()
+	}
+	transport, err := stub.NewTransport(manifest.Flags["proxy-transport"], manifest.ProxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	defer transport.Close()
+	return transport.GetClientInfo()
+}
+
 func trimReport(report *validator.Report) {
 	for i := range report.Stories {
 		for j := range report.Stories[i].Features {
@@ -46,11 +325,21 @@ func trimReport(report *validator.Report) {
 	for i := range report.Features {
 		trimFeatureReport(&report.Features[i])
 	}
+	for i := range report.Benchmarks {
+		report.Benchmarks[i].Records = trimRecords(report.Benchmarks[i].Records)
+	}
+	for i := range report.Soaks {
+		report.Soaks[i].Records = trimRecords(report.Soaks[i].Records)
+	}
 }
 
 func trimFeatureReport(report *validator.FeatureReport) {
-	records := report.Records[:0]
-	for _, r := range report.Records {
+	report.Records = trimRecords(report.Records)
+}
+
+func trimRecords(recorders []validator.Recorder) []validator.Recorder {
+	records := recorders[:0]
+	for _, r := range recorders {
 		if *showRecord == "all" || (*showRecord == "failed" && !r.Success) {
 			if !*showLog {
 				r.Logs = nil
@@ -58,7 +347,7 @@ func trimFeatureReport(report *validator.FeatureReport) {
 			records = append(records, r)
 		}
 	}
-	report.Records = records
+	return records
 }
 
 func printReport(report *validator.Report) {
@@ -80,6 +369,10 @@ func printJSON(report *validator.Report) {
 func printText(report *validator.Report) {
 	hr := strings.Repeat("-", 80)
 
+	if m := report.Manifest; m != nil {
+		fmt.Printf("client: %v, seed: %v\n", clientIdentity(m), m.Seed)
+	}
+
 	printFeature := func(feature *validator.FeatureReport) {
 		fmt.Printf("  + [%v] %v: %v\n", feature.Status, feature.Key, feature.Description)
 		for _, r := range feature.Records {
@@ -105,11 +398,47 @@ func printText(report *validator.Report) {
 		fmt.Println(hr)
 		printFeature(&feature)
 	}
+	if len(report.Mismatches) > 0 {
+		fmt.Println(hr)
+		fmt.Println("# capability mismatches")
+		for _, m := range report.Mismatches {
+			fmt.Printf("  + [%v] %v: %v\n", mismatchStatus(m), m.Key, mismatchText(m))
+		}
+	}
+	if len(report.Benchmarks) > 0 {
+		fmt.Println(hr)
+		fmt.Println("# benchmarks")
+		for _, b := range report.Benchmarks {
+			fmt.Printf("  + [%v] %v: %v\n", b.Status, b.Key, benchSummary(&b))
+			for _, r := range b.Records {
+				fmt.Printf("    - %v\n", r.Description)
+				for _, l := range r.Logs {
+					fmt.Printf("      $ %s\n", l)
+				}
+			}
+		}
+	}
+	for _, soak := range report.Soaks {
+		fmt.Println(hr)
+		fmt.Printf("# soak %v [%v]\n", soak.Key, soak.Status)
+		for _, line := range soakTimeline(&soak) {
+			fmt.Printf("  %s\n", line)
+		}
+		for _, r := range soak.Records {
+			for _, l := range r.Logs {
+				fmt.Printf("    $ %s\n", l)
+			}
+		}
+	}
 }
 
 func printConsole(report *validator.Report) {
 	hr := strings.Repeat("-", 80)
 
+	if m := report.Manifest; m != nil {
+		fmt.Printf("client: %v, seed: %v\n", aurora.Bold(clientIdentity(m)), aurora.Bold(m.Seed))
+	}
+
 	printFeature := func(feature *validator.FeatureReport) {
 		info := aurora.Bold(aurora.Blue(fmt.Sprintf("%s: %s", feature.Key, feature.Description)))
 		fmt.Printf("  [%v] %v\n", colorizeStatus(string(feature.Status)), info)
@@ -137,13 +466,126 @@ func printConsole(report *validator.Report) {
 		fmt.Println(hr)
 		printFeature(&feature)
 	}
+	if len(report.Mismatches) > 0 {
+		fmt.Println(hr)
+		fmt.Println(aurora.Bold(aurora.Magenta("# capability mismatches")))
+		for _, m := range report.Mismatches {
+			fmt.Printf("  [%v] %v\n", colorizeStatus(mismatchStatus(m)), aurora.Bold(aurora.Blue(m.Key+": "+mismatchText(m))))
+		}
+	}
+	if len(report.Benchmarks) > 0 {
+		fmt.Println(hr)
+		fmt.Println(aurora.Bold(aurora.Magenta("# benchmarks")))
+		for _, b := range report.Benchmarks {
+			fmt.Printf("  [%v] %v %v\n", colorizeStatus(string(b.Status)), aurora.Bold(aurora.Blue(b.Key+":")), benchSummary(&b))
+			for _, r := range b.Records {
+				fmt.Printf("    %v\n", aurora.Bold(aurora.Cyan(r.Description)))
+				for _, l := range r.Logs {
+					fmt.Print("      ")
+					fmt.Println(aurora.Gray(8, l))
+				}
+			}
+		}
+	}
+	for _, soak := range report.Soaks {
+		fmt.Println(hr)
+		fmt.Printf("%v [%v]\n", aurora.Bold(aurora.Magenta("# soak "+soak.Key)), colorizeStatus(string(soak.Status)))
+		for _, line := range soakTimeline(&soak) {
+			fmt.Printf("  %s\n", line)
+		}
+		for _, r := range soak.Records {
+			for _, l := range r.Logs {
+				fmt.Print("    ")
+				fmt.Println(aurora.Gray(8, l))
+			}
+		}
+	}
+}
+
+func clientIdentity(m *validator.Manifest) string {
+	if m.ClientName == "" && m.ClientVersion == "" {
+		return "unknown"
+	}
+	identity := strings.TrimSpace(m.ClientName + " " + m.ClientVersion)
+	var extra []string
+	if m.ClientLanguage != "" {
+		extra = append(extra, m.ClientLanguage)
+	}
+	if m.ClientGitCommit != "" {
+		extra = append(extra, m.ClientGitCommit)
+	}
+	if len(extra) > 0 {
+		identity += " (" + strings.Join(extra, ", ") + ")"
+	}
+	return identity
+}
+
+func benchSummary(b *validator.BenchReport) string {
+	if len(b.Rounds) == 0 {
+		return "no result"
+	}
+	return fmt.Sprintf("%.2f ops/s, p50 %v, p99 %v, p999 %v, %d rounds", b.Throughput,
+		b.Latency.Percentile(0.5), b.Latency.Percentile(0.99), b.Latency.Percentile(0.999), len(b.Rounds))
+}
+
+// soakTimeline returns lines of the timeline of a soak, events and anomalies
+// are placed in the windows they happen.
+func soakTimeline(soak *validator.SoakReport) []string {
+	var lines []string
+	events, anomalies := soak.Events, soak.Anomalies
+	for i, w := range soak.Timeline {
+		var ops, errs uint64
+		for _, n := range w.Ops {
+			ops += n
+		}
+		for _, n := range w.Errors {
+			errs += n
+		}
+		line := fmt.Sprintf("%s ops=%d errors=%d p50=%v p99=%v", w.Start.Format("15:04:05"), ops, errs, w.Latency.Percentile(0.5), w.Latency.Percentile(0.99))
+		if w.Goroutines > 0 {
+			line += fmt.Sprintf(" goroutines=%d", w.Goroutines)
+		}
+		lines = append(lines, fmt.Sprintf("%s anomalies=%d", line, w.Anomalies))
+		for _, e := range w.ErrorSamples {
+			lines = append(lines, "  error: "+e)
+		}
+		// The last window takes all events left.
+		inWindow := func(t time.Time) bool {
+			return i+1 == len(soak.Timeline) || t.Before(soak.Timeline[i+1].Start)
+		}
+		for ; len(events) > 0 && inWindow(events[0].Time); events = events[1:] {
+			lines = append(lines, fmt.Sprintf("  %s fault: %s", events[0].Time.Format("15:04:05"), events[0].Message))
+		}
+		for ; len(anomalies) > 0 && inWindow(anomalies[0].Time); anomalies = anomalies[1:] {
+			lines = append(lines, fmt.Sprintf("  %s anomaly: %s", anomalies[0].Time.Format("15:04:05"), anomalies[0].Message))
+		}
+	}
+	return lines
+}
+
+func mismatchStatus(m validator.CapabilityMismatch) string {
+	if m.Status == "" {
+		return "UNKNOWN"
+	}
+	return string(m.Status)
+}
+
+func mismatchText(m validator.CapabilityMismatch) string {
+	switch {
+	case m.Status == "":
+		return "claimed by client but no such feature"
+	case m.Claimed:
+		return "claimed by client but not working"
+	default:
+		return "implemented but not claimed by client"
+	}
 }
 
 func colorizeStatus(text string) aurora.Value {
 	switch text {
 	case "PASS":
 		return aurora.Green(text)
-	case "NOT_IMPL", "SKIP":
+	case "NOT_IMPL", "SKIP", "UNKNOWN":
 		return aurora.Gray(8, text)
 	case "FAIL", "DEFECT":
 		return aurora.Red(text)
@@ -14,7 +14,9 @@
 package mocktikv
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -42,6 +44,186 @@ type MockMember struct {
 	ClientUrls []string `json:"client_urls"`
 }
 
+// MockClock is the request to change the logical clock of a mock cluster.
+// It should be kept synced with mock-tikv.
+type MockClock struct {
+	AdvanceMS int64 `json:"advance_ms"`
+}
+
+// MockFailpoint is the request to enable or disable a failpoint of a mock
+// cluster. It should be kept synced with mock-tikv.
+type MockFailpoint struct {
+	Name   string `json:"name"`
+	Enable bool   `json:"enable"`
+	// Key limits the failpoint to the region containing the key, empty means
+	// all regions.
+	Key []byte `json:"key,omitempty"`
+}
+
+// MockLock is a lock to be planted into a mock cluster, as if it is left by a
+// transaction. It should be kept synced with mock-tikv.
+type MockLock struct {
+	Key     []byte `json:"key"`
+	Value   []byte `json:"value,omitempty"` // value to write when the lock is committed, empty for deletion
+	Primary []byte `json:"primary"`
+	StartTS uint64 `json:"start_ts"`
+	TTL     uint64 `json:"ttl"` // in milliseconds
+	// CommitTS commits the key at the timestamp instead of leaving a lock if it
+	// is not zero. It is used to plant a committed primary key, so that locks
+	// of secondary keys should be rolled forward.
+	CommitTS uint64 `json:"commit_ts,omitempty"`
+}
+
+// StorageMode is the mode of data stored in a mock cluster.
+type StorageMode string
+
+// Storage modes.
+const (
+	// StorageRaw is the data written by rawkv clients.
+	StorageRaw StorageMode = "raw"
+	// StorageTxn is the multi-version data written by txnkv clients.
+	StorageTxn StorageMode = "txn"
+)
+
+// MockKV is a key-value pair stored in a mock cluster. It should be kept synced
+// with mock-tikv.
+type MockKV struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value,omitempty"` // empty for a deletion version in txn mode
+	CF    string `json:"cf,omitempty"`    // for raw mode, empty means default
+	// StartTS and CommitTS are the timestamps of the version in txn mode. They
+	// are allocated by mock-tikv when loading data if they are zero.
+	StartTS  uint64 `json:"start_ts,omitempty"`
+	CommitTS uint64 `json:"commit_ts,omitempty"`
+}
+
+// MockData is the request to load data into a mock cluster, or the response of
+// dumping data from a mock cluster. It should be kept synced with mock-tikv.
+type MockData struct {
+	Mode StorageMode `json:"mode"`
+	KVs  []MockKV    `json:"kvs,omitempty"`
+}
+
+// MockRange is the request to dump data in range [StartKey, EndKey) from a
+// mock cluster. An empty EndKey means no upper bound. It should be kept synced
+// with mock-tikv.
+type MockRange struct {
+	Mode     StorageMode `json:"mode"`
+	CF       string      `json:"cf,omitempty"` // for raw mode, empty means default
+	StartKey []byte      `json:"start_key,omitempty"`
+	EndKey   []byte      `json:"end_key,omitempty"`
+}
+
+// MockGCSafePoint is the request to set the GC safe point of a mock cluster.
+// It should be kept synced with mock-tikv.
+type MockGCSafePoint struct {
+	SafePoint uint64 `json:"safe_point"`
+}
+
+// MockSplit is the request to split regions of a mock cluster at keys. It
+// should be kept synced with mock-tikv.
+type MockSplit struct {
+	Keys [][]byte `json:"keys"`
+}
+
+// MockTransferLeader is the request to move the leader of the region containing
+// the key to another store. It should be kept synced with mock-tikv.
+type MockTransferLeader struct {
+	Key []byte `json:"key"`
+}
+
+// MockMerge is the request to merge the region containing the key into the
+// region before it. It should be kept synced with mock-tikv.
+type MockMerge struct {
+	Key []byte `json:"key"`
+}
+
+// MockMoveRegion is the request to move all peers of the region containing the
+// key to other stores. It should be kept synced with mock-tikv.
+type MockMoveRegion struct {
+	Key []byte `json:"key"`
+}
+
+// MockConfig is the request to change settings of a mock cluster, zero fields
+// are left unchanged. It should be kept synced with mock-tikv.
+type MockConfig struct {
+	// MaxKeySize is the max size (in bytes) of a key, larger keys are rejected.
+	MaxKeySize uint64 `json:"max_key_size,omitempty"`
+	// RaftEntryMaxSize is the max size (in bytes) of a write request to a
+	// region, larger requests are rejected.
+	RaftEntryMaxSize uint64 `json:"raft_entry_max_size,omitempty"`
+}
+
+// MockStats is the RPC statistics of a mock cluster, counted since the cluster
+// is created or the statistics are reset. It should be kept synced with
+// mock-tikv.
+type MockStats struct {
+	// Commands counts requests received by TiKV servers by command, named as
+	// the rpc of tikvpb, e.g. "KvBatchGet", "RawPut".
+	Commands map[string]uint64 `json:"commands,omitempty"`
+	// RegionErrors counts region errors returned to clients by kind, e.g.
+	// "NotLeader", "EpochNotMatch".
+	RegionErrors map[string]uint64 `json:"region_errors,omitempty"`
+	// Retries counts requests by command which are sent again after a region
+	// error is returned for the same keys.
+	Retries map[string]uint64 `json:"retries,omitempty"`
+	// PDCommands counts requests received by PD servers by command, named as
+	// the rpc of pdpb, e.g. "GetRegion", "Tso". Each request on a Tso stream
+	// is counted, no matter how many timestamps it asks for.
+	PDCommands map[string]uint64 `json:"pd_commands,omitempty"`
+}
+
+// TotalRegionErrors returns the count of all region errors.
+func (s *MockStats) TotalRegionErrors() uint64 {
+	return sum(s.RegionErrors)
+}
+
+// TotalRetries returns the count of all retried requests.
+func (s *MockStats) TotalRetries() uint64 {
+	return sum(s.Retries)
+}
+
+// RegionLookups returns the count of requests to PD querying regions, which
+// are sent when the client loads or refreshes its region cache.
+func (s *MockStats) RegionLookups() uint64 {
+	var n uint64
+	for _, cmd := range []string{"GetRegion", "GetPrevRegion", "GetRegionByID", "ScanRegions"} {
+		n += s.PDCommands[cmd]
+	}
+	return n
+}
+
+func sum(m map[string]uint64) uint64 {
+	var n uint64
+	for _, v := range m {
+		n += v
+	}
+	return n
+}
+
+// MockStatsRequest is the request to query RPC statistics of a mock cluster.
+// It should be kept synced with mock-tikv.
+type MockStatsRequest struct {
+	// Reset clears the statistics after they are returned.
+	Reset bool `json:"reset,omitempty"`
+}
+
+// Failpoints supported by mock-tikv.
+const (
+	// FailpointBeforeCommitPrimary rejects all commit requests, as if the
+	// client crashes after prewrite and before committing the primary key.
+	FailpointBeforeCommitPrimary = "before-commit-primary"
+	// FailpointBeforeCommitSecondaries rejects commit requests of secondary
+	// keys, as if the client crashes after committing the primary key.
+	FailpointBeforeCommitSecondaries = "before-commit-secondaries"
+	// FailpointWriteError rejects write requests (e.g. raw put, raw delete,
+	// prewrite) with an error which should not be retried.
+	FailpointWriteError = "write-error"
+	// FailpointPDUnavailable drops all requests to PD servers without a
+	// response, as if PD is unreachable.
+	FailpointPDUnavailable = "pd-unavailable"
+)
+
 // Cluster represents a mock cluster in mock-tikv server.
 type Cluster struct {
 	mockServer string
@@ -97,3 +279,136 @@ func (c *Cluster) Close() error {
 	_, err = httpClient.Do(req)
 	return errors.WithStack(err)
 }
+
+// AdvanceClock moves the logical clock of the mock cluster forward. The clock
+// is used to allocate timestamps and to decide expiration of keys and locks
+// (e.g. rawkv TTL, lock TTL), so tests can control time deterministically
+// instead of sleeping.
+func (c *Cluster) AdvanceClock(d time.Duration) error {
+	return c.post("clock", &MockClock{AdvanceMS: int64(d / time.Millisecond)}, nil)
+}
+
+// Split splits regions of the mock cluster at keys, so that each key is the
+// start key of a region. Keys which are already region boundaries are ignored.
+func (c *Cluster) Split(keys ...[]byte) error {
+	return c.post("regions/split", &MockSplit{Keys: keys}, nil)
+}
+
+// TransferLeader moves the leader of the region containing the key to another
+// store, requests sent to the old leader get NotLeader errors.
+func (c *Cluster) TransferLeader(key []byte) error {
+	return c.post("regions/transfer-leader", &MockTransferLeader{Key: key}, nil)
+}
+
+// Merge merges the region containing the key into the region before it, so
+// that the start key of the region is no longer a region boundary. Requests
+// sent to the merged region get EpochNotMatch errors.
+func (c *Cluster) Merge(key []byte) error {
+	return c.post("regions/merge", &MockMerge{Key: key}, nil)
+}
+
+// MoveRegion moves all peers of the region containing the key to other stores,
+// requests sent to the old stores get RegionNotFound errors.
+func (c *Cluster) MoveRegion(key []byte) error {
+	return c.post("regions/move", &MockMoveRegion{Key: key}, nil)
+}
+
+// FailoverPD makes another PD member the leader, as if the PD leader crashes.
+// Requests sent to the old leader are rejected.
+func (c *Cluster) FailoverPD() error {
+	return c.post("pd/failover", struct{}{}, nil)
+}
+
+// Configure changes settings of the mock cluster.
+func (c *Cluster) Configure(cfg MockConfig) error {
+	return c.post("config", &cfg, nil)
+}
+
+// Load writes key-value pairs into the mock cluster directly, bypassing the
+// client. It is used to prepare data for read operations.
+func (c *Cluster) Load(mode StorageMode, kvs []MockKV) error {
+	return c.post("data/load", &MockData{Mode: mode, KVs: kvs}, nil)
+}
+
+// Dump reads key-value pairs in a range from the mock cluster directly,
+// bypassing the client. It is used to verify data written by write operations.
+// In txn mode, all committed versions are returned, ordered by key and then
+// by commit timestamp descending.
+func (c *Cluster) Dump(r MockRange) ([]MockKV, error) {
+	var data MockData
+	if err := c.post("data/dump", &r, &data); err != nil {
+		return nil, err
+	}
+	return data.KVs, nil
+}
+
+// PutLock plants a lock into the mock cluster directly, bypassing the client.
+// It is used to simulate locks left by dead transactions.
+func (c *Cluster) PutLock(lock MockLock) error {
+	return c.post("locks", &lock, nil)
+}
+
+// SetGCSafePoint sets the GC safe point of the mock cluster. Versions older than
+// the safe point may be removed, and reads with timestamps below it should be
+// rejected.
+func (c *Cluster) SetGCSafePoint(ts uint64) error {
+	return c.post("gc-safepoint", &MockGCSafePoint{SafePoint: ts}, nil)
+}
+
+// EnableFailpoint enables a failpoint of the mock cluster.
+func (c *Cluster) EnableFailpoint(name string) error {
+	return c.post("failpoints", &MockFailpoint{Name: name, Enable: true}, nil)
+}
+
+// EnableRegionFailpoint enables a failpoint of the mock cluster for the region
+// containing the key only.
+func (c *Cluster) EnableRegionFailpoint(name string, key []byte) error {
+	return c.post("failpoints", &MockFailpoint{Name: name, Enable: true, Key: key}, nil)
+}
+
+// DisableFailpoint disables a failpoint of the mock cluster in all regions.
+func (c *Cluster) DisableFailpoint(name string) error {
+	return c.post("failpoints", &MockFailpoint{Name: name}, nil)
+}
+
+// Stats returns the RPC statistics of the mock cluster. It is used to check
+// whether the client sends requests efficiently.
+func (c *Cluster) Stats() (*MockStats, error) {
+	var stats MockStats
+	if err := c.post("stats", &MockStatsRequest{}, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// ResetStats clears the RPC statistics of the mock cluster, so that following
+// requests are counted from zero.
+func (c *Cluster) ResetStats() error {
+	return c.post("stats", &MockStatsRequest{Reset: true}, nil)
+}
+
+// post sends a request to an API of the mock cluster. The response is decoded
+// to res if it is not nil.
+func (c *Cluster) post(api string, req interface{}, res interface{}) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	url := fmt.Sprintf("%s/mock-tikv/api/v1/clusters/%d/%s", c.mockServer, c.clusterID, api)
+	resp, err := httpClient.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.New(string(data))
+	}
+	if res == nil {
+		return nil
+	}
+	return errors.WithStack(json.Unmarshal(data, res))
+}
@@ -0,0 +1,924 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The gRPC protocol of the client proxy server. It is equivalent to the http
+// protocol: each rpc corresponds to a route of the http proxy, the id in the
+// route is passed by the id field of the request, and errors are returned as
+// the status message. Messages may exceed the default 4MB limit of grpc,
+// servers should raise the max receive and send message sizes.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: proxy.proto
+
+package proxypb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type InfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InfoRequest) Reset() {
+	*x = InfoRequest{}
+	mi := &file_proxy_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InfoRequest) ProtoMessage() {}
+
+func (x *InfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InfoRequest.ProtoReflect.Descriptor instead.
+func (*InfoRequest) Descriptor() ([]byte, []int) {
+	return file_proxy_proto_rawDescGZIP(), []int{0}
+}
+
+type ClientInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Language      string                 `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	GitCommit     string                 `protobuf:"bytes,4,opt,name=git_commit,json=gitCommit,proto3" json:"git_commit,omitempty"`
+	Capabilities  []string               `protobuf:"bytes,5,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClientInfo) Reset() {
+	*x = ClientInfo{}
+	mi := &file_proxy_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientInfo) ProtoMessage() {}
+
+func (x *ClientInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientInfo.ProtoReflect.Descriptor instead.
+func (*ClientInfo) Descriptor() ([]byte, []int) {
+	return file_proxy_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ClientInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ClientInfo) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *ClientInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ClientInfo) GetGitCommit() string {
+	if x != nil {
+		return x.GitCommit
+	}
+	return ""
+}
+
+func (x *ClientInfo) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+type RawRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	PdAddrs          []string               `protobuf:"bytes,2,rep,name=pd_addrs,json=pdAddrs,proto3" json:"pd_addrs,omitempty"`
+	Key              []byte                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Keys             [][]byte               `protobuf:"bytes,4,rep,name=keys,proto3" json:"keys,omitempty"`
+	Value            []byte                 `protobuf:"bytes,5,opt,name=value,proto3" json:"value,omitempty"`
+	Values           [][]byte               `protobuf:"bytes,6,rep,name=values,proto3" json:"values,omitempty"`
+	Ttl              uint64                 `protobuf:"varint,7,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	Ttls             []uint64               `protobuf:"varint,8,rep,packed,name=ttls,proto3" json:"ttls,omitempty"`
+	StartKey         []byte                 `protobuf:"bytes,9,opt,name=start_key,json=startKey,proto3" json:"start_key,omitempty"`
+	EndKey           []byte                 `protobuf:"bytes,10,opt,name=end_key,json=endKey,proto3" json:"end_key,omitempty"`
+	Limit            int64                  `protobuf:"varint,11,opt,name=limit,proto3" json:"limit,omitempty"`
+	Reverse          bool                   `protobuf:"varint,12,opt,name=reverse,proto3" json:"reverse,omitempty"`
+	KeyOnly          bool                   `protobuf:"varint,13,opt,name=key_only,json=keyOnly,proto3" json:"key_only,omitempty"`
+	Cf               string                 `protobuf:"bytes,14,opt,name=cf,proto3" json:"cf,omitempty"`
+	PreviousValue    []byte                 `protobuf:"bytes,15,opt,name=previous_value,json=previousValue,proto3" json:"previous_value,omitempty"`
+	PreviousNotExist bool                   `protobuf:"varint,16,opt,name=previous_not_exist,json=previousNotExist,proto3" json:"previous_not_exist,omitempty"`
+	Atomic           bool                   `protobuf:"varint,17,opt,name=atomic,proto3" json:"atomic,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *RawRequest) Reset() {
+	*x = RawRequest{}
+	mi := &file_proxy_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RawRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RawRequest) ProtoMessage() {}
+
+func (x *RawRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RawRequest.ProtoReflect.Descriptor instead.
+func (*RawRequest) Descriptor() ([]byte, []int) {
+	return file_proxy_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RawRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RawRequest) GetPdAddrs() []string {
+	if x != nil {
+		return x.PdAddrs
+	}
+	return nil
+}
+
+func (x *RawRequest) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *RawRequest) GetKeys() [][]byte {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *RawRequest) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *RawRequest) GetValues() [][]byte {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *RawRequest) GetTtl() uint64 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *RawRequest) GetTtls() []uint64 {
+	if x != nil {
+		return x.Ttls
+	}
+	return nil
+}
+
+func (x *RawRequest) GetStartKey() []byte {
+	if x != nil {
+		return x.StartKey
+	}
+	return nil
+}
+
+func (x *RawRequest) GetEndKey() []byte {
+	if x != nil {
+		return x.EndKey
+	}
+	return nil
+}
+
+func (x *RawRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *RawRequest) GetReverse() bool {
+	if x != nil {
+		return x.Reverse
+	}
+	return false
+}
+
+func (x *RawRequest) GetKeyOnly() bool {
+	if x != nil {
+		return x.KeyOnly
+	}
+	return false
+}
+
+func (x *RawRequest) GetCf() string {
+	if x != nil {
+		return x.Cf
+	}
+	return ""
+}
+
+func (x *RawRequest) GetPreviousValue() []byte {
+	if x != nil {
+		return x.PreviousValue
+	}
+	return nil
+}
+
+func (x *RawRequest) GetPreviousNotExist() bool {
+	if x != nil {
+		return x.PreviousNotExist
+	}
+	return false
+}
+
+func (x *RawRequest) GetAtomic() bool {
+	if x != nil {
+		return x.Atomic
+	}
+	return false
+}
+
+type RawResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Value            []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Keys             [][]byte               `protobuf:"bytes,3,rep,name=keys,proto3" json:"keys,omitempty"`
+	Values           [][]byte               `protobuf:"bytes,4,rep,name=values,proto3" json:"values,omitempty"`
+	Ttl              *uint64                `protobuf:"varint,5,opt,name=ttl,proto3,oneof" json:"ttl,omitempty"` // unset if key does not exist
+	PreviousValue    []byte                 `protobuf:"bytes,6,opt,name=previous_value,json=previousValue,proto3" json:"previous_value,omitempty"`
+	PreviousNotExist bool                   `protobuf:"varint,7,opt,name=previous_not_exist,json=previousNotExist,proto3" json:"previous_not_exist,omitempty"`
+	Succeed          bool                   `protobuf:"varint,8,opt,name=succeed,proto3" json:"succeed,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *RawResponse) Reset() {
+	*x = RawResponse{}
+	mi := &file_proxy_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RawResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RawResponse) ProtoMessage() {}
+
+func (x *RawResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RawResponse.ProtoReflect.Descriptor instead.
+func (*RawResponse) Descriptor() ([]byte, []int) {
+	return file_proxy_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RawResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RawResponse) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *RawResponse) GetKeys() [][]byte {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *RawResponse) GetValues() [][]byte {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *RawResponse) GetTtl() uint64 {
+	if x != nil && x.Ttl != nil {
+		return *x.Ttl
+	}
+	return 0
+}
+
+func (x *RawResponse) GetPreviousValue() []byte {
+	if x != nil {
+		return x.PreviousValue
+	}
+	return nil
+}
+
+func (x *RawResponse) GetPreviousNotExist() bool {
+	if x != nil {
+		return x.PreviousNotExist
+	}
+	return false
+}
+
+func (x *RawResponse) GetSucceed() bool {
+	if x != nil {
+		return x.Succeed
+	}
+	return false
+}
+
+type TxnRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	PdAddrs         []string               `protobuf:"bytes,2,rep,name=pd_addrs,json=pdAddrs,proto3" json:"pd_addrs,omitempty"`
+	Ts              uint64                 `protobuf:"varint,3,opt,name=ts,proto3" json:"ts,omitempty"`
+	Key             []byte                 `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
+	Value           []byte                 `protobuf:"bytes,5,opt,name=value,proto3" json:"value,omitempty"`
+	Keys            [][]byte               `protobuf:"bytes,6,rep,name=keys,proto3" json:"keys,omitempty"`
+	UpperBound      []byte                 `protobuf:"bytes,7,opt,name=upper_bound,json=upperBound,proto3" json:"upper_bound,omitempty"`
+	Pessimistic     bool                   `protobuf:"varint,8,opt,name=pessimistic,proto3" json:"pessimistic,omitempty"`
+	AsyncCommit     bool                   `protobuf:"varint,9,opt,name=async_commit,json=asyncCommit,proto3" json:"async_commit,omitempty"`
+	OnePc           bool                   `protobuf:"varint,10,opt,name=one_pc,json=onePc,proto3" json:"one_pc,omitempty"`
+	LockWaitTimeout int64                  `protobuf:"varint,11,opt,name=lock_wait_timeout,json=lockWaitTimeout,proto3" json:"lock_wait_timeout,omitempty"`
+	EntrySizeLimit  uint64                 `protobuf:"varint,12,opt,name=entry_size_limit,json=entrySizeLimit,proto3" json:"entry_size_limit,omitempty"`
+	TotalSizeLimit  uint64                 `protobuf:"varint,13,opt,name=total_size_limit,json=totalSizeLimit,proto3" json:"total_size_limit,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TxnRequest) Reset() {
+	*x = TxnRequest{}
+	mi := &file_proxy_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TxnRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TxnRequest) ProtoMessage() {}
+
+func (x *TxnRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TxnRequest.ProtoReflect.Descriptor instead.
+func (*TxnRequest) Descriptor() ([]byte, []int) {
+	return file_proxy_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TxnRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TxnRequest) GetPdAddrs() []string {
+	if x != nil {
+		return x.PdAddrs
+	}
+	return nil
+}
+
+func (x *TxnRequest) GetTs() uint64 {
+	if x != nil {
+		return x.Ts
+	}
+	return 0
+}
+
+func (x *TxnRequest) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *TxnRequest) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *TxnRequest) GetKeys() [][]byte {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *TxnRequest) GetUpperBound() []byte {
+	if x != nil {
+		return x.UpperBound
+	}
+	return nil
+}
+
+func (x *TxnRequest) GetPessimistic() bool {
+	if x != nil {
+		return x.Pessimistic
+	}
+	return false
+}
+
+func (x *TxnRequest) GetAsyncCommit() bool {
+	if x != nil {
+		return x.AsyncCommit
+	}
+	return false
+}
+
+func (x *TxnRequest) GetOnePc() bool {
+	if x != nil {
+		return x.OnePc
+	}
+	return false
+}
+
+func (x *TxnRequest) GetLockWaitTimeout() int64 {
+	if x != nil {
+		return x.LockWaitTimeout
+	}
+	return 0
+}
+
+func (x *TxnRequest) GetEntrySizeLimit() uint64 {
+	if x != nil {
+		return x.EntrySizeLimit
+	}
+	return 0
+}
+
+func (x *TxnRequest) GetTotalSizeLimit() uint64 {
+	if x != nil {
+		return x.TotalSizeLimit
+	}
+	return 0
+}
+
+type TxnResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Ts            uint64                 `protobuf:"varint,2,opt,name=ts,proto3" json:"ts,omitempty"`
+	Key           []byte                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Value         []byte                 `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	Keys          [][]byte               `protobuf:"bytes,5,rep,name=keys,proto3" json:"keys,omitempty"`
+	Values        [][]byte               `protobuf:"bytes,6,rep,name=values,proto3" json:"values,omitempty"`
+	IsValid       bool                   `protobuf:"varint,7,opt,name=is_valid,json=isValid,proto3" json:"is_valid,omitempty"`
+	IsReadonly    bool                   `protobuf:"varint,8,opt,name=is_readonly,json=isReadonly,proto3" json:"is_readonly,omitempty"`
+	Size          int64                  `protobuf:"varint,9,opt,name=size,proto3" json:"size,omitempty"`
+	Length        int64                  `protobuf:"varint,10,opt,name=length,proto3" json:"length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TxnResponse) Reset() {
+	*x = TxnResponse{}
+	mi := &file_proxy_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TxnResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TxnResponse) ProtoMessage() {}
+
+func (x *TxnResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TxnResponse.ProtoReflect.Descriptor instead.
+func (*TxnResponse) Descriptor() ([]byte, []int) {
+	return file_proxy_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TxnResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TxnResponse) GetTs() uint64 {
+	if x != nil {
+		return x.Ts
+	}
+	return 0
+}
+
+func (x *TxnResponse) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *TxnResponse) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *TxnResponse) GetKeys() [][]byte {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *TxnResponse) GetValues() [][]byte {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *TxnResponse) GetIsValid() bool {
+	if x != nil {
+		return x.IsValid
+	}
+	return false
+}
+
+func (x *TxnResponse) GetIsReadonly() bool {
+	if x != nil {
+		return x.IsReadonly
+	}
+	return false
+}
+
+func (x *TxnResponse) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *TxnResponse) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+var File_proxy_proto protoreflect.FileDescriptor
+
+const file_proxy_proto_rawDesc = "" +
+	"\n" +
+	"\vproxy.proto\x12\aproxypb\"\r\n" +
+	"\vInfoRequest\"\x99\x01\n" +
+	"\n" +
+	"ClientInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\blanguage\x18\x02 \x01(\tR\blanguage\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12\x1d\n" +
+	"\n" +
+	"git_commit\x18\x04 \x01(\tR\tgitCommit\x12\"\n" +
+	"\fcapabilities\x18\x05 \x03(\tR\fcapabilities\"\xaf\x03\n" +
+	"\n" +
+	"RawRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bpd_addrs\x18\x02 \x03(\tR\apdAddrs\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\fR\x03key\x12\x12\n" +
+	"\x04keys\x18\x04 \x03(\fR\x04keys\x12\x14\n" +
+	"\x05value\x18\x05 \x01(\fR\x05value\x12\x16\n" +
+	"\x06values\x18\x06 \x03(\fR\x06values\x12\x10\n" +
+	"\x03ttl\x18\a \x01(\x04R\x03ttl\x12\x12\n" +
+	"\x04ttls\x18\b \x03(\x04R\x04ttls\x12\x1b\n" +
+	"\tstart_key\x18\t \x01(\fR\bstartKey\x12\x17\n" +
+	"\aend_key\x18\n" +
+	" \x01(\fR\x06endKey\x12\x14\n" +
+	"\x05limit\x18\v \x01(\x03R\x05limit\x12\x18\n" +
+	"\areverse\x18\f \x01(\bR\areverse\x12\x19\n" +
+	"\bkey_only\x18\r \x01(\bR\akeyOnly\x12\x0e\n" +
+	"\x02cf\x18\x0e \x01(\tR\x02cf\x12%\n" +
+	"\x0eprevious_value\x18\x0f \x01(\fR\rpreviousValue\x12,\n" +
+	"\x12previous_not_exist\x18\x10 \x01(\bR\x10previousNotExist\x12\x16\n" +
+	"\x06atomic\x18\x11 \x01(\bR\x06atomic\"\xed\x01\n" +
+	"\vRawResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\x12\x12\n" +
+	"\x04keys\x18\x03 \x03(\fR\x04keys\x12\x16\n" +
+	"\x06values\x18\x04 \x03(\fR\x06values\x12\x15\n" +
+	"\x03ttl\x18\x05 \x01(\x04H\x00R\x03ttl\x88\x01\x01\x12%\n" +
+	"\x0eprevious_value\x18\x06 \x01(\fR\rpreviousValue\x12,\n" +
+	"\x12previous_not_exist\x18\a \x01(\bR\x10previousNotExist\x12\x18\n" +
+	"\asucceed\x18\b \x01(\bR\asucceedB\x06\n" +
+	"\x04_ttl\"\x80\x03\n" +
+	"\n" +
+	"TxnRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bpd_addrs\x18\x02 \x03(\tR\apdAddrs\x12\x0e\n" +
+	"\x02ts\x18\x03 \x01(\x04R\x02ts\x12\x10\n" +
+	"\x03key\x18\x04 \x01(\fR\x03key\x12\x14\n" +
+	"\x05value\x18\x05 \x01(\fR\x05value\x12\x12\n" +
+	"\x04keys\x18\x06 \x03(\fR\x04keys\x12\x1f\n" +
+	"\vupper_bound\x18\a \x01(\fR\n" +
+	"upperBound\x12 \n" +
+	"\vpessimistic\x18\b \x01(\bR\vpessimistic\x12!\n" +
+	"\fasync_commit\x18\t \x01(\bR\vasyncCommit\x12\x15\n" +
+	"\x06one_pc\x18\n" +
+	" \x01(\bR\x05onePc\x12*\n" +
+	"\x11lock_wait_timeout\x18\v \x01(\x03R\x0flockWaitTimeout\x12(\n" +
+	"\x10entry_size_limit\x18\f \x01(\x04R\x0eentrySizeLimit\x12(\n" +
+	"\x10total_size_limit\x18\r \x01(\x04R\x0etotalSizeLimit\"\xe9\x01\n" +
+	"\vTxnResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x0e\n" +
+	"\x02ts\x18\x02 \x01(\x04R\x02ts\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\fR\x03key\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\fR\x05value\x12\x12\n" +
+	"\x04keys\x18\x05 \x03(\fR\x04keys\x12\x16\n" +
+	"\x06values\x18\x06 \x03(\fR\x06values\x12\x19\n" +
+	"\bis_valid\x18\a \x01(\bR\aisValid\x12\x1f\n" +
+	"\vis_readonly\x18\b \x01(\bR\n" +
+	"isReadonly\x12\x12\n" +
+	"\x04size\x18\t \x01(\x03R\x04size\x12\x16\n" +
+	"\x06length\x18\n" +
+	" \x01(\x03R\x06length2:\n" +
+	"\x05Proxy\x121\n" +
+	"\x04Info\x12\x14.proxypb.InfoRequest\x1a\x13.proxypb.ClientInfo2\xce\x05\n" +
+	"\x05RawKV\x120\n" +
+	"\x03New\x12\x13.proxypb.RawRequest\x1a\x14.proxypb.RawResponse\x122\n" +
+	"\x05Close\x12\x13.proxypb.RawRequest\x1a\x14.proxypb.RawResponse\x120\n" +
+	"\x03Get\x12\x13.proxypb.RawRequest\x1a\x14.proxypb.RawResponse\x125\n" +
+	"\bBatchGet\x12\x13.proxypb.RawRequest\x1a\x14.proxypb.RawResponse\x120\n" +
+	"\x03Put\x12\x13.proxypb.RawRequest\x1a\x14.proxypb.RawResponse\x125\n" +
+	"\bBatchPut\x12\x13.proxypb.RawRequest\x1a\x14.proxypb.RawResponse\x126\n" +
+	"\tGetKeyTTL\x12\x13.proxypb.RawRequest\x1a\x14.proxypb.RawResponse\x12<\n" +
+	"\x0fSetAtomicForCAS\x12\x13.proxypb.RawRequest\x1a\x14.proxypb.RawResponse\x12;\n" +
+	"\x0eCompareAndSwap\x12\x13.proxypb.RawRequest\x1a\x14.proxypb.RawResponse\x123\n" +
+	"\x06Delete\x12\x13.proxypb.RawRequest\x1a\x14.proxypb.RawResponse\x128\n" +
+	"\vBatchDelete\x12\x13.proxypb.RawRequest\x1a\x14.proxypb.RawResponse\x128\n" +
+	"\vDeleteRange\x12\x13.proxypb.RawRequest\x1a\x14.proxypb.RawResponse\x121\n" +
+	"\x04Scan\x12\x13.proxypb.RawRequest\x1a\x14.proxypb.RawResponse2\xfb\t\n" +
+	"\x05TxnKV\x120\n" +
+	"\x03New\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x122\n" +
+	"\x05Close\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x122\n" +
+	"\x05Begin\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x128\n" +
+	"\vBeginWithTS\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x122\n" +
+	"\x05GetTS\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x123\n" +
+	"\x06TxnGet\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x128\n" +
+	"\vTxnBatchGet\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x123\n" +
+	"\x06TxnSet\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x126\n" +
+	"\tTxnDelete\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x124\n" +
+	"\aTxnIter\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x12;\n" +
+	"\x0eTxnIterReverse\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x128\n" +
+	"\vTxnReadonly\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x126\n" +
+	"\tTxnCommit\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x128\n" +
+	"\vTxnRollback\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x128\n" +
+	"\vTxnLockKeys\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x125\n" +
+	"\bTxnValid\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x123\n" +
+	"\x06TxnLen\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x124\n" +
+	"\aTxnSize\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x126\n" +
+	"\tIterValid\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x124\n" +
+	"\aIterKey\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x126\n" +
+	"\tIterValue\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x125\n" +
+	"\bIterNext\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponse\x126\n" +
+	"\tIterClose\x12\x13.proxypb.TxnRequest\x1a\x14.proxypb.TxnResponseB*Z(github.com/tikv/client-validator/proxypbb\x06proto3"
+
+var (
+	file_proxy_proto_rawDescOnce sync.Once
+	file_proxy_proto_rawDescData []byte
+)
+
+func file_proxy_proto_rawDescGZIP() []byte {
+	file_proxy_proto_rawDescOnce.Do(func() {
+		file_proxy_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proxy_proto_rawDesc), len(file_proxy_proto_rawDesc)))
+	})
+	return file_proxy_proto_rawDescData
+}
+
+var file_proxy_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proxy_proto_goTypes = []any{
+	(*InfoRequest)(nil), // 0: proxypb.InfoRequest
+	(*ClientInfo)(nil),  // 1: proxypb.ClientInfo
+	(*RawRequest)(nil),  // 2: proxypb.RawRequest
+	(*RawResponse)(nil), // 3: proxypb.RawResponse
+	(*TxnRequest)(nil),  // 4: proxypb.TxnRequest
+	(*TxnResponse)(nil), // 5: proxypb.TxnResponse
+}
+var file_proxy_proto_depIdxs = []int32{
+	0,  // 0: proxypb.Proxy.Info:input_type -> proxypb.InfoRequest
+	2,  // 1: proxypb.RawKV.New:input_type -> proxypb.RawRequest
+	2,  // 2: proxypb.RawKV.Close:input_type -> proxypb.RawRequest
+	2,  // 3: proxypb.RawKV.Get:input_type -> proxypb.RawRequest
+	2,  // 4: proxypb.RawKV.BatchGet:input_type -> proxypb.RawRequest
+	2,  // 5: proxypb.RawKV.Put:input_type -> proxypb.RawRequest
+	2,  // 6: proxypb.RawKV.BatchPut:input_type -> proxypb.RawRequest
+	2,  // 7: proxypb.RawKV.GetKeyTTL:input_type -> proxypb.RawRequest
+	2,  // 8: proxypb.RawKV.SetAtomicForCAS:input_type -> proxypb.RawRequest
+	2,  // 9: proxypb.RawKV.CompareAndSwap:input_type -> proxypb.RawRequest
+	2,  // 10: proxypb.RawKV.Delete:input_type -> proxypb.RawRequest
+	2,  // 11: proxypb.RawKV.BatchDelete:input_type -> proxypb.RawRequest
+	2,  // 12: proxypb.RawKV.DeleteRange:input_type -> proxypb.RawRequest
+	2,  // 13: proxypb.RawKV.Scan:input_type -> proxypb.RawRequest
+	4,  // 14: proxypb.TxnKV.New:input_type -> proxypb.TxnRequest
+	4,  // 15: proxypb.TxnKV.Close:input_type -> proxypb.TxnRequest
+	4,  // 16: proxypb.TxnKV.Begin:input_type -> proxypb.TxnRequest
+	4,  // 17: proxypb.TxnKV.BeginWithTS:input_type -> proxypb.TxnRequest
+	4,  // 18: proxypb.TxnKV.GetTS:input_type -> proxypb.TxnRequest
+	4,  // 19: proxypb.TxnKV.TxnGet:input_type -> proxypb.TxnRequest
+	4,  // 20: proxypb.TxnKV.TxnBatchGet:input_type -> proxypb.TxnRequest
+	4,  // 21: proxypb.TxnKV.TxnSet:input_type -> proxypb.TxnRequest
+	4,  // 22: proxypb.TxnKV.TxnDelete:input_type -> proxypb.TxnRequest
+	4,  // 23: proxypb.TxnKV.TxnIter:input_type -> proxypb.TxnRequest
+	4,  // 24: proxypb.TxnKV.TxnIterReverse:input_type -> proxypb.TxnRequest
+	4,  // 25: proxypb.TxnKV.TxnReadonly:input_type -> proxypb.TxnRequest
+	4,  // 26: proxypb.TxnKV.TxnCommit:input_type -> proxypb.TxnRequest
+	4,  // 27: proxypb.TxnKV.TxnRollback:input_type -> proxypb.TxnRequest
+	4,  // 28: proxypb.TxnKV.TxnLockKeys:input_type -> proxypb.TxnRequest
+	4,  // 29: proxypb.TxnKV.TxnValid:input_type -> proxypb.TxnRequest
+	4,  // 30: proxypb.TxnKV.TxnLen:input_type -> proxypb.TxnRequest
+	4,  // 31: proxypb.TxnKV.TxnSize:input_type -> proxypb.TxnRequest
+	4,  // 32: proxypb.TxnKV.IterValid:input_type -> proxypb.TxnRequest
+	4,  // 33: proxypb.TxnKV.IterKey:input_type -> proxypb.TxnRequest
+	4,  // 34: proxypb.TxnKV.IterValue:input_type -> proxypb.TxnRequest
+	4,  // 35: proxypb.TxnKV.IterNext:input_type -> proxypb.TxnRequest
+	4,  // 36: proxypb.TxnKV.IterClose:input_type -> proxypb.TxnRequest
+	1,  // 37: proxypb.Proxy.Info:output_type -> proxypb.ClientInfo
+	3,  // 38: proxypb.RawKV.New:output_type -> proxypb.RawResponse
+	3,  // 39: proxypb.RawKV.Close:output_type -> proxypb.RawResponse
+	3,  // 40: proxypb.RawKV.Get:output_type -> proxypb.RawResponse
+	3,  // 41: proxypb.RawKV.BatchGet:output_type -> proxypb.RawResponse
+	3,  // 42: proxypb.RawKV.Put:output_type -> proxypb.RawResponse
+	3,  // 43: proxypb.RawKV.BatchPut:output_type -> proxypb.RawResponse
+	3,  // 44: proxypb.RawKV.GetKeyTTL:output_type -> proxypb.RawResponse
+	3,  // 45: proxypb.RawKV.SetAtomicForCAS:output_type -> proxypb.RawResponse
+	3,  // 46: proxypb.RawKV.CompareAndSwap:output_type -> proxypb.RawResponse
+	3,  // 47: proxypb.RawKV.Delete:output_type -> proxypb.RawResponse
+	3,  // 48: proxypb.RawKV.BatchDelete:output_type -> proxypb.RawResponse
+	3,  // 49: proxypb.RawKV.DeleteRange:output_type -> proxypb.RawResponse
+	3,  // 50: proxypb.RawKV.Scan:output_type -> proxypb.RawResponse
+	5,  // 51: proxypb.TxnKV.New:output_type -> proxypb.TxnResponse
+	5,  // 52: proxypb.TxnKV.Close:output_type -> proxypb.TxnResponse
+	5,  // 53: proxypb.TxnKV.Begin:output_type -> proxypb.TxnResponse
+	5,  // 54: proxypb.TxnKV.BeginWithTS:output_type -> proxypb.TxnResponse
+	5,  // 55: proxypb.TxnKV.GetTS:output_type -> proxypb.TxnResponse
+	5,  // 56: proxypb.TxnKV.TxnGet:output_type -> proxypb.TxnResponse
+	5,  // 57: proxypb.TxnKV.TxnBatchGet:output_type -> proxypb.TxnResponse
+	5,  // 58: proxypb.TxnKV.TxnSet:output_type -> proxypb.TxnResponse
+	5,  // 59: proxypb.TxnKV.TxnDelete:output_type -> proxypb.TxnResponse
+	5,  // 60: proxypb.TxnKV.TxnIter:output_type -> proxypb.TxnResponse
+	5,  // 61: proxypb.TxnKV.TxnIterReverse:output_type -> proxypb.TxnResponse
+	5,  // 62: proxypb.TxnKV.TxnReadonly:output_type -> proxypb.TxnResponse
+	5,  // 63: proxypb.TxnKV.TxnCommit:output_type -> proxypb.TxnResponse
+	5,  // 64: proxypb.TxnKV.TxnRollback:output_type -> proxypb.TxnResponse
+	5,  // 65: proxypb.TxnKV.TxnLockKeys:output_type -> proxypb.TxnResponse
+	5,  // 66: proxypb.TxnKV.TxnValid:output_type -> proxypb.TxnResponse
+	5,  // 67: proxypb.TxnKV.TxnLen:output_type -> proxypb.TxnResponse
+	5,  // 68: proxypb.TxnKV.TxnSize:output_type -> proxypb.TxnResponse
+	5,  // 69: proxypb.TxnKV.IterValid:output_type -> proxypb.TxnResponse
+	5,  // 70: proxypb.TxnKV.IterKey:output_type -> proxypb.TxnResponse
+	5,  // 71: proxypb.TxnKV.IterValue:output_type -> proxypb.TxnResponse
+	5,  // 72: proxypb.TxnKV.IterNext:output_type -> proxypb.TxnResponse
+	5,  // 73: proxypb.TxnKV.IterClose:output_type -> proxypb.TxnResponse
+	37, // [37:74] is the sub-list for method output_type
+	0,  // [0:37] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_proxy_proto_init() }
+func file_proxy_proto_init() {
+	if File_proxy_proto != nil {
+		return
+	}
+	file_proxy_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proxy_proto_rawDesc), len(file_proxy_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   3,
+		},
+		GoTypes:           file_proxy_proto_goTypes,
+		DependencyIndexes: file_proxy_proto_depIdxs,
+		MessageInfos:      file_proxy_proto_msgTypes,
+	}.Build()
+	File_proxy_proto = out.File
+	file_proxy_proto_goTypes = nil
+	file_proxy_proto_depIdxs = nil
+}
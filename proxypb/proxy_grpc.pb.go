@@ -0,0 +1,1722 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The gRPC protocol of the client proxy server. It is equivalent to the http
+// protocol: each rpc corresponds to a route of the http proxy, the id in the
+// route is passed by the id field of the request, and errors are returned as
+// the status message. Messages may exceed the default 4MB limit of grpc,
+// servers should raise the max receive and send message sizes.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: proxy.proto
+
+package proxypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Proxy_Info_FullMethodName = "/proxypb.Proxy/Info"
+)
+
+// ProxyClient is the client API for Proxy service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Proxy reports the client behind the proxy server.
+type ProxyClient interface {
+	// GET /info
+	Info(ctx context.Context, in *InfoRequest, opts ...grpc.CallOption) (*ClientInfo, error)
+}
+
+type proxyClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProxyClient(cc grpc.ClientConnInterface) ProxyClient {
+	return &proxyClient{cc}
+}
+
+func (c *proxyClient) Info(ctx context.Context, in *InfoRequest, opts ...grpc.CallOption) (*ClientInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClientInfo)
+	err := c.cc.Invoke(ctx, Proxy_Info_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProxyServer is the server API for Proxy service.
+// All implementations must embed UnimplementedProxyServer
+// for forward compatibility.
+//
+// Proxy reports the client behind the proxy server.
+type ProxyServer interface {
+	// GET /info
+	Info(context.Context, *InfoRequest) (*ClientInfo, error)
+	mustEmbedUnimplementedProxyServer()
+}
+
+// UnimplementedProxyServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedProxyServer struct{}
+
+func (UnimplementedProxyServer) Info(context.Context, *InfoRequest) (*ClientInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Info not implemented")
+}
+func (UnimplementedProxyServer) mustEmbedUnimplementedProxyServer() {}
+func (UnimplementedProxyServer) testEmbeddedByValue()               {}
+
+// UnsafeProxyServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProxyServer will
+// result in compilation errors.
+type UnsafeProxyServer interface {
+	mustEmbedUnimplementedProxyServer()
+}
+
+func RegisterProxyServer(s grpc.ServiceRegistrar, srv ProxyServer) {
+	// If the following call pancis, it indicates UnimplementedProxyServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Proxy_ServiceDesc, srv)
+}
+
+func _Proxy_Info_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProxyServer).Info(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Proxy_Info_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProxyServer).Info(ctx, req.(*InfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Proxy_ServiceDesc is the grpc.ServiceDesc for Proxy service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Proxy_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proxypb.Proxy",
+	HandlerType: (*ProxyServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Info",
+			Handler:    _Proxy_Info_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proxy.proto",
+}
+
+const (
+	RawKV_New_FullMethodName             = "/proxypb.RawKV/New"
+	RawKV_Close_FullMethodName           = "/proxypb.RawKV/Close"
+	RawKV_Get_FullMethodName             = "/proxypb.RawKV/Get"
+	RawKV_BatchGet_FullMethodName        = "/proxypb.RawKV/BatchGet"
+	RawKV_Put_FullMethodName             = "/proxypb.RawKV/Put"
+	RawKV_BatchPut_FullMethodName        = "/proxypb.RawKV/BatchPut"
+	RawKV_GetKeyTTL_FullMethodName       = "/proxypb.RawKV/GetKeyTTL"
+	RawKV_SetAtomicForCAS_FullMethodName = "/proxypb.RawKV/SetAtomicForCAS"
+	RawKV_CompareAndSwap_FullMethodName  = "/proxypb.RawKV/CompareAndSwap"
+	RawKV_Delete_FullMethodName          = "/proxypb.RawKV/Delete"
+	RawKV_BatchDelete_FullMethodName     = "/proxypb.RawKV/BatchDelete"
+	RawKV_DeleteRange_FullMethodName     = "/proxypb.RawKV/DeleteRange"
+	RawKV_Scan_FullMethodName            = "/proxypb.RawKV/Scan"
+)
+
+// RawKVClient is the client API for RawKV service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// RawKV redirects calls to a rawkv client.
+type RawKVClient interface {
+	// /rawkv/client/new
+	New(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error)
+	// /rawkv/client/{id}/close
+	Close(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error)
+	// /rawkv/client/{id}/get
+	Get(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error)
+	// /rawkv/client/{id}/batch-get
+	BatchGet(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error)
+	// /rawkv/client/{id}/put
+	Put(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error)
+	// /rawkv/client/{id}/batch-put
+	BatchPut(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error)
+	// /rawkv/client/{id}/get-key-ttl
+	GetKeyTTL(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error)
+	// /rawkv/client/{id}/set-atomic-for-cas
+	SetAtomicForCAS(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error)
+	// /rawkv/client/{id}/compare-and-swap
+	CompareAndSwap(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error)
+	// /rawkv/client/{id}/delete
+	Delete(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error)
+	// /rawkv/client/{id}/batch-delete
+	BatchDelete(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error)
+	// /rawkv/client/{id}/delete-range
+	DeleteRange(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error)
+	// /rawkv/client/{id}/scan
+	Scan(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error)
+}
+
+type rawKVClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRawKVClient(cc grpc.ClientConnInterface) RawKVClient {
+	return &rawKVClient{cc}
+}
+
+func (c *rawKVClient) New(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RawResponse)
+	err := c.cc.Invoke(ctx, RawKV_New_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rawKVClient) Close(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RawResponse)
+	err := c.cc.Invoke(ctx, RawKV_Close_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rawKVClient) Get(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RawResponse)
+	err := c.cc.Invoke(ctx, RawKV_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rawKVClient) BatchGet(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RawResponse)
+	err := c.cc.Invoke(ctx, RawKV_BatchGet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rawKVClient) Put(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RawResponse)
+	err := c.cc.Invoke(ctx, RawKV_Put_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rawKVClient) BatchPut(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RawResponse)
+	err := c.cc.Invoke(ctx, RawKV_BatchPut_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rawKVClient) GetKeyTTL(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RawResponse)
+	err := c.cc.Invoke(ctx, RawKV_GetKeyTTL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rawKVClient) SetAtomicForCAS(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RawResponse)
+	err := c.cc.Invoke(ctx, RawKV_SetAtomicForCAS_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rawKVClient) CompareAndSwap(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RawResponse)
+	err := c.cc.Invoke(ctx, RawKV_CompareAndSwap_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rawKVClient) Delete(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RawResponse)
+	err := c.cc.Invoke(ctx, RawKV_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rawKVClient) BatchDelete(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RawResponse)
+	err := c.cc.Invoke(ctx, RawKV_BatchDelete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rawKVClient) DeleteRange(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RawResponse)
+	err := c.cc.Invoke(ctx, RawKV_DeleteRange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rawKVClient) Scan(ctx context.Context, in *RawRequest, opts ...grpc.CallOption) (*RawResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RawResponse)
+	err := c.cc.Invoke(ctx, RawKV_Scan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RawKVServer is the server API for RawKV service.
+// All implementations must embed UnimplementedRawKVServer
+// for forward compatibility.
+//
+// RawKV redirects calls to a rawkv client.
+type RawKVServer interface {
+	// /rawkv/client/new
+	New(context.Context, *RawRequest) (*RawResponse, error)
+	// /rawkv/client/{id}/close
+	Close(context.Context, *RawRequest) (*RawResponse, error)
+	// /rawkv/client/{id}/get
+	Get(context.Context, *RawRequest) (*RawResponse, error)
+	// /rawkv/client/{id}/batch-get
+	BatchGet(context.Context, *RawRequest) (*RawResponse, error)
+	// /rawkv/client/{id}/put
+	Put(context.Context, *RawRequest) (*RawResponse, error)
+	// /rawkv/client/{id}/batch-put
+	BatchPut(context.Context, *RawRequest) (*RawResponse, error)
+	// /rawkv/client/{id}/get-key-ttl
+	GetKeyTTL(context.Context, *RawRequest) (*RawResponse, error)
+	// /rawkv/client/{id}/set-atomic-for-cas
+	SetAtomicForCAS(context.Context, *RawRequest) (*RawResponse, error)
+	// /rawkv/client/{id}/compare-and-swap
+	CompareAndSwap(context.Context, *RawRequest) (*RawResponse, error)
+	// /rawkv/client/{id}/delete
+	Delete(context.Context, *RawRequest) (*RawResponse, error)
+	// /rawkv/client/{id}/batch-delete
+	BatchDelete(context.Context, *RawRequest) (*RawResponse, error)
+	// /rawkv/client/{id}/delete-range
+	DeleteRange(context.Context, *RawRequest) (*RawResponse, error)
+	// /rawkv/client/{id}/scan
+	Scan(context.Context, *RawRequest) (*RawResponse, error)
+	mustEmbedUnimplementedRawKVServer()
+}
+
+// UnimplementedRawKVServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRawKVServer struct{}
+
+func (UnimplementedRawKVServer) New(context.Context, *RawRequest) (*RawResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method New not implemented")
+}
+func (UnimplementedRawKVServer) Close(context.Context, *RawRequest) (*RawResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Close not implemented")
+}
+func (UnimplementedRawKVServer) Get(context.Context, *RawRequest) (*RawResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedRawKVServer) BatchGet(context.Context, *RawRequest) (*RawResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGet not implemented")
+}
+func (UnimplementedRawKVServer) Put(context.Context, *RawRequest) (*RawResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Put not implemented")
+}
+func (UnimplementedRawKVServer) BatchPut(context.Context, *RawRequest) (*RawResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchPut not implemented")
+}
+func (UnimplementedRawKVServer) GetKeyTTL(context.Context, *RawRequest) (*RawResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKeyTTL not implemented")
+}
+func (UnimplementedRawKVServer) SetAtomicForCAS(context.Context, *RawRequest) (*RawResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAtomicForCAS not implemented")
+}
+func (UnimplementedRawKVServer) CompareAndSwap(context.Context, *RawRequest) (*RawResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompareAndSwap not implemented")
+}
+func (UnimplementedRawKVServer) Delete(context.Context, *RawRequest) (*RawResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedRawKVServer) BatchDelete(context.Context, *RawRequest) (*RawResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDelete not implemented")
+}
+func (UnimplementedRawKVServer) DeleteRange(context.Context, *RawRequest) (*RawResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRange not implemented")
+}
+func (UnimplementedRawKVServer) Scan(context.Context, *RawRequest) (*RawResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Scan not implemented")
+}
+func (UnimplementedRawKVServer) mustEmbedUnimplementedRawKVServer() {}
+func (UnimplementedRawKVServer) testEmbeddedByValue()               {}
+
+// UnsafeRawKVServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RawKVServer will
+// result in compilation errors.
+type UnsafeRawKVServer interface {
+	mustEmbedUnimplementedRawKVServer()
+}
+
+func RegisterRawKVServer(s grpc.ServiceRegistrar, srv RawKVServer) {
+	// If the following call pancis, it indicates UnimplementedRawKVServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RawKV_ServiceDesc, srv)
+}
+
+func _RawKV_New_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawKVServer).New(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RawKV_New_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawKVServer).New(ctx, req.(*RawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RawKV_Close_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawKVServer).Close(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RawKV_Close_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawKVServer).Close(ctx, req.(*RawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RawKV_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawKVServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RawKV_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawKVServer).Get(ctx, req.(*RawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RawKV_BatchGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawKVServer).BatchGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RawKV_BatchGet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawKVServer).BatchGet(ctx, req.(*RawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RawKV_Put_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawKVServer).Put(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RawKV_Put_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawKVServer).Put(ctx, req.(*RawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RawKV_BatchPut_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawKVServer).BatchPut(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RawKV_BatchPut_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawKVServer).BatchPut(ctx, req.(*RawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RawKV_GetKeyTTL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawKVServer).GetKeyTTL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RawKV_GetKeyTTL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawKVServer).GetKeyTTL(ctx, req.(*RawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RawKV_SetAtomicForCAS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawKVServer).SetAtomicForCAS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RawKV_SetAtomicForCAS_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawKVServer).SetAtomicForCAS(ctx, req.(*RawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RawKV_CompareAndSwap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawKVServer).CompareAndSwap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RawKV_CompareAndSwap_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawKVServer).CompareAndSwap(ctx, req.(*RawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RawKV_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawKVServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RawKV_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawKVServer).Delete(ctx, req.(*RawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RawKV_BatchDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawKVServer).BatchDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RawKV_BatchDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawKVServer).BatchDelete(ctx, req.(*RawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RawKV_DeleteRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawKVServer).DeleteRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RawKV_DeleteRange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawKVServer).DeleteRange(ctx, req.(*RawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RawKV_Scan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RawKVServer).Scan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RawKV_Scan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RawKVServer).Scan(ctx, req.(*RawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RawKV_ServiceDesc is the grpc.ServiceDesc for RawKV service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RawKV_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proxypb.RawKV",
+	HandlerType: (*RawKVServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "New",
+			Handler:    _RawKV_New_Handler,
+		},
+		{
+			MethodName: "Close",
+			Handler:    _RawKV_Close_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _RawKV_Get_Handler,
+		},
+		{
+			MethodName: "BatchGet",
+			Handler:    _RawKV_BatchGet_Handler,
+		},
+		{
+			MethodName: "Put",
+			Handler:    _RawKV_Put_Handler,
+		},
+		{
+			MethodName: "BatchPut",
+			Handler:    _RawKV_BatchPut_Handler,
+		},
+		{
+			MethodName: "GetKeyTTL",
+			Handler:    _RawKV_GetKeyTTL_Handler,
+		},
+		{
+			MethodName: "SetAtomicForCAS",
+			Handler:    _RawKV_SetAtomicForCAS_Handler,
+		},
+		{
+			MethodName: "CompareAndSwap",
+			Handler:    _RawKV_CompareAndSwap_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _RawKV_Delete_Handler,
+		},
+		{
+			MethodName: "BatchDelete",
+			Handler:    _RawKV_BatchDelete_Handler,
+		},
+		{
+			MethodName: "DeleteRange",
+			Handler:    _RawKV_DeleteRange_Handler,
+		},
+		{
+			MethodName: "Scan",
+			Handler:    _RawKV_Scan_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proxy.proto",
+}
+
+const (
+	TxnKV_New_FullMethodName            = "/proxypb.TxnKV/New"
+	TxnKV_Close_FullMethodName          = "/proxypb.TxnKV/Close"
+	TxnKV_Begin_FullMethodName          = "/proxypb.TxnKV/Begin"
+	TxnKV_BeginWithTS_FullMethodName    = "/proxypb.TxnKV/BeginWithTS"
+	TxnKV_GetTS_FullMethodName          = "/proxypb.TxnKV/GetTS"
+	TxnKV_TxnGet_FullMethodName         = "/proxypb.TxnKV/TxnGet"
+	TxnKV_TxnBatchGet_FullMethodName    = "/proxypb.TxnKV/TxnBatchGet"
+	TxnKV_TxnSet_FullMethodName         = "/proxypb.TxnKV/TxnSet"
+	TxnKV_TxnDelete_FullMethodName      = "/proxypb.TxnKV/TxnDelete"
+	TxnKV_TxnIter_FullMethodName        = "/proxypb.TxnKV/TxnIter"
+	TxnKV_TxnIterReverse_FullMethodName = "/proxypb.TxnKV/TxnIterReverse"
+	TxnKV_TxnReadonly_FullMethodName    = "/proxypb.TxnKV/TxnReadonly"
+	TxnKV_TxnCommit_FullMethodName      = "/proxypb.TxnKV/TxnCommit"
+	TxnKV_TxnRollback_FullMethodName    = "/proxypb.TxnKV/TxnRollback"
+	TxnKV_TxnLockKeys_FullMethodName    = "/proxypb.TxnKV/TxnLockKeys"
+	TxnKV_TxnValid_FullMethodName       = "/proxypb.TxnKV/TxnValid"
+	TxnKV_TxnLen_FullMethodName         = "/proxypb.TxnKV/TxnLen"
+	TxnKV_TxnSize_FullMethodName        = "/proxypb.TxnKV/TxnSize"
+	TxnKV_IterValid_FullMethodName      = "/proxypb.TxnKV/IterValid"
+	TxnKV_IterKey_FullMethodName        = "/proxypb.TxnKV/IterKey"
+	TxnKV_IterValue_FullMethodName      = "/proxypb.TxnKV/IterValue"
+	TxnKV_IterNext_FullMethodName       = "/proxypb.TxnKV/IterNext"
+	TxnKV_IterClose_FullMethodName      = "/proxypb.TxnKV/IterClose"
+)
+
+// TxnKVClient is the client API for TxnKV service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TxnKV redirects calls to a txnkv client, its transactions and iterators.
+type TxnKVClient interface {
+	// /txnkv/client/new
+	New(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/client/{id}/close
+	Close(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/client/{id}/begin
+	Begin(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/client/{id}/begin-with-ts
+	BeginWithTS(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/client/{id}/get-ts
+	GetTS(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/txn/{id}/get
+	TxnGet(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/txn/{id}/batch-get
+	TxnBatchGet(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/txn/{id}/set
+	TxnSet(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/txn/{id}/delete
+	TxnDelete(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/txn/{id}/iter
+	TxnIter(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/txn/{id}/iter-reverse
+	TxnIterReverse(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/txn/{id}/readonly
+	TxnReadonly(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/txn/{id}/commit
+	TxnCommit(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/txn/{id}/rollback
+	TxnRollback(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/txn/{id}/lock-keys
+	TxnLockKeys(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/txn/{id}/valid
+	TxnValid(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/txn/{id}/len
+	TxnLen(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/txn/{id}/size
+	TxnSize(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/iter/{id}/valid
+	IterValid(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/iter/{id}/key
+	IterKey(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/iter/{id}/value
+	IterValue(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/iter/{id}/next
+	IterNext(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+	// /txnkv/iter/{id}/close
+	IterClose(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
+}
+
+type txnKVClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTxnKVClient(cc grpc.ClientConnInterface) TxnKVClient {
+	return &txnKVClient{cc}
+}
+
+func (c *txnKVClient) New(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_New_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) Close(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_Close_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) Begin(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_Begin_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) BeginWithTS(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_BeginWithTS_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) GetTS(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_GetTS_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) TxnGet(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_TxnGet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) TxnBatchGet(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_TxnBatchGet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) TxnSet(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_TxnSet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) TxnDelete(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_TxnDelete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) TxnIter(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_TxnIter_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) TxnIterReverse(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_TxnIterReverse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) TxnReadonly(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_TxnReadonly_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) TxnCommit(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_TxnCommit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) TxnRollback(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_TxnRollback_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) TxnLockKeys(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_TxnLockKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) TxnValid(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_TxnValid_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) TxnLen(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_TxnLen_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) TxnSize(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_TxnSize_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) IterValid(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_IterValid_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) IterKey(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_IterKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) IterValue(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_IterValue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) IterNext(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_IterNext_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnKVClient) IterClose(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, TxnKV_IterClose_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TxnKVServer is the server API for TxnKV service.
+// All implementations must embed UnimplementedTxnKVServer
+// for forward compatibility.
+//
+// TxnKV redirects calls to a txnkv client, its transactions and iterators.
+type TxnKVServer interface {
+	// /txnkv/client/new
+	New(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/client/{id}/close
+	Close(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/client/{id}/begin
+	Begin(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/client/{id}/begin-with-ts
+	BeginWithTS(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/client/{id}/get-ts
+	GetTS(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/txn/{id}/get
+	TxnGet(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/txn/{id}/batch-get
+	TxnBatchGet(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/txn/{id}/set
+	TxnSet(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/txn/{id}/delete
+	TxnDelete(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/txn/{id}/iter
+	TxnIter(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/txn/{id}/iter-reverse
+	TxnIterReverse(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/txn/{id}/readonly
+	TxnReadonly(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/txn/{id}/commit
+	TxnCommit(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/txn/{id}/rollback
+	TxnRollback(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/txn/{id}/lock-keys
+	TxnLockKeys(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/txn/{id}/valid
+	TxnValid(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/txn/{id}/len
+	TxnLen(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/txn/{id}/size
+	TxnSize(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/iter/{id}/valid
+	IterValid(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/iter/{id}/key
+	IterKey(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/iter/{id}/value
+	IterValue(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/iter/{id}/next
+	IterNext(context.Context, *TxnRequest) (*TxnResponse, error)
+	// /txnkv/iter/{id}/close
+	IterClose(context.Context, *TxnRequest) (*TxnResponse, error)
+	mustEmbedUnimplementedTxnKVServer()
+}
+
+// UnimplementedTxnKVServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTxnKVServer struct{}
+
+func (UnimplementedTxnKVServer) New(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method New not implemented")
+}
+func (UnimplementedTxnKVServer) Close(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Close not implemented")
+}
+func (UnimplementedTxnKVServer) Begin(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Begin not implemented")
+}
+func (UnimplementedTxnKVServer) BeginWithTS(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BeginWithTS not implemented")
+}
+func (UnimplementedTxnKVServer) GetTS(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTS not implemented")
+}
+func (UnimplementedTxnKVServer) TxnGet(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxnGet not implemented")
+}
+func (UnimplementedTxnKVServer) TxnBatchGet(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxnBatchGet not implemented")
+}
+func (UnimplementedTxnKVServer) TxnSet(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxnSet not implemented")
+}
+func (UnimplementedTxnKVServer) TxnDelete(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxnDelete not implemented")
+}
+func (UnimplementedTxnKVServer) TxnIter(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxnIter not implemented")
+}
+func (UnimplementedTxnKVServer) TxnIterReverse(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxnIterReverse not implemented")
+}
+func (UnimplementedTxnKVServer) TxnReadonly(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxnReadonly not implemented")
+}
+func (UnimplementedTxnKVServer) TxnCommit(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxnCommit not implemented")
+}
+func (UnimplementedTxnKVServer) TxnRollback(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxnRollback not implemented")
+}
+func (UnimplementedTxnKVServer) TxnLockKeys(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxnLockKeys not implemented")
+}
+func (UnimplementedTxnKVServer) TxnValid(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxnValid not implemented")
+}
+func (UnimplementedTxnKVServer) TxnLen(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxnLen not implemented")
+}
+func (UnimplementedTxnKVServer) TxnSize(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxnSize not implemented")
+}
+func (UnimplementedTxnKVServer) IterValid(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IterValid not implemented")
+}
+func (UnimplementedTxnKVServer) IterKey(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IterKey not implemented")
+}
+func (UnimplementedTxnKVServer) IterValue(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IterValue not implemented")
+}
+func (UnimplementedTxnKVServer) IterNext(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IterNext not implemented")
+}
+func (UnimplementedTxnKVServer) IterClose(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IterClose not implemented")
+}
+func (UnimplementedTxnKVServer) mustEmbedUnimplementedTxnKVServer() {}
+func (UnimplementedTxnKVServer) testEmbeddedByValue()               {}
+
+// UnsafeTxnKVServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TxnKVServer will
+// result in compilation errors.
+type UnsafeTxnKVServer interface {
+	mustEmbedUnimplementedTxnKVServer()
+}
+
+func RegisterTxnKVServer(s grpc.ServiceRegistrar, srv TxnKVServer) {
+	// If the following call pancis, it indicates UnimplementedTxnKVServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TxnKV_ServiceDesc, srv)
+}
+
+func _TxnKV_New_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).New(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_New_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).New(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_Close_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).Close(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_Close_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).Close(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_Begin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).Begin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_Begin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).Begin(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_BeginWithTS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).BeginWithTS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_BeginWithTS_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).BeginWithTS(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_GetTS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).GetTS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_GetTS_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).GetTS(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_TxnGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).TxnGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_TxnGet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).TxnGet(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_TxnBatchGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).TxnBatchGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_TxnBatchGet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).TxnBatchGet(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_TxnSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).TxnSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_TxnSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).TxnSet(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_TxnDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).TxnDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_TxnDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).TxnDelete(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_TxnIter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).TxnIter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_TxnIter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).TxnIter(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_TxnIterReverse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).TxnIterReverse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_TxnIterReverse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).TxnIterReverse(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_TxnReadonly_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).TxnReadonly(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_TxnReadonly_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).TxnReadonly(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_TxnCommit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).TxnCommit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_TxnCommit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).TxnCommit(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_TxnRollback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).TxnRollback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_TxnRollback_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).TxnRollback(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_TxnLockKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).TxnLockKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_TxnLockKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).TxnLockKeys(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_TxnValid_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).TxnValid(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_TxnValid_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).TxnValid(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_TxnLen_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).TxnLen(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_TxnLen_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).TxnLen(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_TxnSize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).TxnSize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_TxnSize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).TxnSize(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_IterValid_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).IterValid(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_IterValid_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).IterValid(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_IterKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).IterKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_IterKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).IterKey(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_IterValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).IterValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_IterValue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).IterValue(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_IterNext_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).IterNext(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_IterNext_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).IterNext(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnKV_IterClose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnKVServer).IterClose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TxnKV_IterClose_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnKVServer).IterClose(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TxnKV_ServiceDesc is the grpc.ServiceDesc for TxnKV service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TxnKV_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proxypb.TxnKV",
+	HandlerType: (*TxnKVServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "New",
+			Handler:    _TxnKV_New_Handler,
+		},
+		{
+			MethodName: "Close",
+			Handler:    _TxnKV_Close_Handler,
+		},
+		{
+			MethodName: "Begin",
+			Handler:    _TxnKV_Begin_Handler,
+		},
+		{
+			MethodName: "BeginWithTS",
+			Handler:    _TxnKV_BeginWithTS_Handler,
+		},
+		{
+			MethodName: "GetTS",
+			Handler:    _TxnKV_GetTS_Handler,
+		},
+		{
+			MethodName: "TxnGet",
+			Handler:    _TxnKV_TxnGet_Handler,
+		},
+		{
+			MethodName: "TxnBatchGet",
+			Handler:    _TxnKV_TxnBatchGet_Handler,
+		},
+		{
+			MethodName: "TxnSet",
+			Handler:    _TxnKV_TxnSet_Handler,
+		},
+		{
+			MethodName: "TxnDelete",
+			Handler:    _TxnKV_TxnDelete_Handler,
+		},
+		{
+			MethodName: "TxnIter",
+			Handler:    _TxnKV_TxnIter_Handler,
+		},
+		{
+			MethodName: "TxnIterReverse",
+			Handler:    _TxnKV_TxnIterReverse_Handler,
+		},
+		{
+			MethodName: "TxnReadonly",
+			Handler:    _TxnKV_TxnReadonly_Handler,
+		},
+		{
+			MethodName: "TxnCommit",
+			Handler:    _TxnKV_TxnCommit_Handler,
+		},
+		{
+			MethodName: "TxnRollback",
+			Handler:    _TxnKV_TxnRollback_Handler,
+		},
+		{
+			MethodName: "TxnLockKeys",
+			Handler:    _TxnKV_TxnLockKeys_Handler,
+		},
+		{
+			MethodName: "TxnValid",
+			Handler:    _TxnKV_TxnValid_Handler,
+		},
+		{
+			MethodName: "TxnLen",
+			Handler:    _TxnKV_TxnLen_Handler,
+		},
+		{
+			MethodName: "TxnSize",
+			Handler:    _TxnKV_TxnSize_Handler,
+		},
+		{
+			MethodName: "IterValid",
+			Handler:    _TxnKV_IterValid_Handler,
+		},
+		{
+			MethodName: "IterKey",
+			Handler:    _TxnKV_IterKey_Handler,
+		},
+		{
+			MethodName: "IterValue",
+			Handler:    _TxnKV_IterValue_Handler,
+		},
+		{
+			MethodName: "IterNext",
+			Handler:    _TxnKV_IterNext_Handler,
+		},
+		{
+			MethodName: "IterClose",
+			Handler:    _TxnKV_IterClose_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proxy.proto",
+}
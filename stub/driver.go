@@ -0,0 +1,65 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stub
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ClientProxy is the name of the default client, which is a proxy server
+// reached by a Transport.
+const ClientProxy = "proxy"
+
+// Driver creates clients which are called in-process, without a proxy server.
+// Adapters register drivers by RegisterDriver in their init functions.
+type Driver interface {
+	// NewRawKV creates a rawkv client connected to the pd servers.
+	NewRawKV(pdAddrs []string) (RawKV, error)
+	// NewTxnKV creates a txnkv client connected to the pd servers.
+	NewTxnKV(pdAddrs []string) (TxnKV, error)
+	// GetClientInfo returns the identity of the client.
+	GetClientInfo() (*ClientInfo, error)
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Driver)
+)
+
+// RegisterDriver makes a driver available by the name. It panics if the name
+// is registered twice.
+func RegisterDriver(name string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if name == ClientProxy {
+		panic("stub: driver name " + name + " is reserved")
+	}
+	if _, ok := drivers[name]; ok {
+		panic("stub: driver " + name + " is registered twice")
+	}
+	drivers[name] = driver
+}
+
+// GetDriver returns the driver registered by the name.
+func GetDriver(name string) (Driver, error) {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	driver, ok := drivers[name]
+	if !ok {
+		return nil, errors.Errorf("unknown client: %s", name)
+	}
+	return driver, nil
+}
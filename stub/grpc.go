@@ -0,0 +1,234 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stub
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tikv/client-validator/proxypb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// grpcMethods maps routes of the http proxy to methods of the grpc proxy.
+var grpcMethods = map[string]string{
+	"/rawkv/client/new":                     proxypb.RawKV_New_FullMethodName,
+	"/rawkv/client/{id}/close":              proxypb.RawKV_Close_FullMethodName,
+	"/rawkv/client/{id}/get":                proxypb.RawKV_Get_FullMethodName,
+	"/rawkv/client/{id}/batch-get":          proxypb.RawKV_BatchGet_FullMethodName,
+	"/rawkv/client/{id}/put":                proxypb.RawKV_Put_FullMethodName,
+	"/rawkv/client/{id}/batch-put":          proxypb.RawKV_BatchPut_FullMethodName,
+	"/rawkv/client/{id}/get-key-ttl":        proxypb.RawKV_GetKeyTTL_FullMethodName,
+	"/rawkv/client/{id}/set-atomic-for-cas": proxypb.RawKV_SetAtomicForCAS_FullMethodName,
+	"/rawkv/client/{id}/compare-and-swap":   proxypb.RawKV_CompareAndSwap_FullMethodName,
+	"/rawkv/client/{id}/delete":             proxypb.RawKV_Delete_FullMethodName,
+	"/rawkv/client/{id}/batch-delete":       proxypb.RawKV_BatchDelete_FullMethodName,
+	"/rawkv/client/{id}/delete-range":       proxypb.RawKV_DeleteRange_FullMethodName,
+	"/rawkv/client/{id}/scan":               proxypb.RawKV_Scan_FullMethodName,
+
+	"/txnkv/client/new":                proxypb.TxnKV_New_FullMethodName,
+	"/txnkv/client/{id}/close":         proxypb.TxnKV_Close_FullMethodName,
+	"/txnkv/client/{id}/begin":         proxypb.TxnKV_Begin_FullMethodName,
+	"/txnkv/client/{id}/begin-with-ts": proxypb.TxnKV_BeginWithTS_FullMethodName,
+	"/txnkv/client/{id}/get-ts":        proxypb.TxnKV_GetTS_FullMethodName,
+	"/txnkv/txn/{id}/get":              proxypb.TxnKV_TxnGet_FullMethodName,
+	"/txnkv/txn/{id}/batch-get":        proxypb.TxnKV_TxnBatchGet_FullMethodName,
+	"/txnkv/txn/{id}/set":              proxypb.TxnKV_TxnSet_FullMethodName,
+	"/txnkv/txn/{id}/delete":           proxypb.TxnKV_TxnDelete_FullMethodName,
+	"/txnkv/txn/{id}/iter":             proxypb.TxnKV_TxnIter_FullMethodName,
+	"/txnkv/txn/{id}/iter-reverse":     proxypb.TxnKV_TxnIterReverse_FullMethodName,
+	"/txnkv/txn/{id}/readonly":         proxypb.TxnKV_TxnReadonly_FullMethodName,
+	"/txnkv/txn/{id}/commit":           proxypb.TxnKV_TxnCommit_FullMethodName,
+	"/txnkv/txn/{id}/rollback":         proxypb.TxnKV_TxnRollback_FullMethodName,
+	"/txnkv/txn/{id}/lock-keys":        proxypb.TxnKV_TxnLockKeys_FullMethodName,
+	"/txnkv/txn/{id}/valid":            proxypb.TxnKV_TxnValid_FullMethodName,
+	"/txnkv/txn/{id}/len":              proxypb.TxnKV_TxnLen_FullMethodName,
+	"/txnkv/txn/{id}/size":             proxypb.TxnKV_TxnSize_FullMethodName,
+	"/txnkv/iter/{id}/valid":           proxypb.TxnKV_IterValid_FullMethodName,
+	"/txnkv/iter/{id}/key":             proxypb.TxnKV_IterKey_FullMethodName,
+	"/txnkv/iter/{id}/value":           proxypb.TxnKV_IterValue_FullMethodName,
+	"/txnkv/iter/{id}/next":            proxypb.TxnKV_IterNext_FullMethodName,
+	"/txnkv/iter/{id}/close":           proxypb.TxnKV_IterClose_FullMethodName,
+}
+
+// GRPCTransport sends requests as protobuf messages to a grpc proxy server.
+type GRPCTransport struct {
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+// NewGRPCTransport creates a transport to a grpc proxy server. The scheme of
+// the address (e.g. "http://") is ignored.
+func NewGRPCTransport(proxyServer string) (*GRPCTransport, error) {
+	if i := strings.Index(proxyServer, "://"); i >= 0 {
+		proxyServer = proxyServer[i+3:]
+	}
+	conn, err := grpc.NewClient(strings.TrimSuffix(proxyServer, "/"),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(math.MaxInt32), grpc.MaxCallSendMsgSize(math.MaxInt32)))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &GRPCTransport{
+		conn:    conn,
+		timeout: CallTimeout,
+	}, nil
+}
+
+// SendRaw sends a rawkv request.
+func (t *GRPCTransport) SendRaw(route string, req *RawRequest) (*RawResponse, error) {
+	method, id, err := t.parseRoute(route)
+	if err != nil {
+		return nil, err
+	}
+	in := &proxypb.RawRequest{
+		Id:               id,
+		PdAddrs:          req.PDAddrs,
+		Key:              req.Key,
+		Keys:             req.Keys,
+		Value:            req.Value,
+		Values:           req.Values,
+		Ttl:              req.TTL,
+		Ttls:             req.TTLs,
+		StartKey:         req.StartKey,
+		EndKey:           req.EndKey,
+		Limit:            int64(req.Limit),
+		Reverse:          req.Reverse,
+		KeyOnly:          req.KeyOnly,
+		Cf:               req.CF,
+		PreviousValue:    req.PreviousValue,
+		PreviousNotExist: req.PreviousNotExist,
+		Atomic:           req.Atomic,
+	}
+	var out proxypb.RawResponse
+	if err = t.invoke(method, in, &out); err != nil {
+		return nil, err
+	}
+	return &RawResponse{
+		ID:               out.Id,
+		Value:            out.Value,
+		Keys:             out.Keys,
+		Values:           out.Values,
+		TTL:              out.Ttl,
+		PreviousValue:    out.PreviousValue,
+		PreviousNotExist: out.PreviousNotExist,
+		Succeed:          out.Succeed,
+	}, nil
+}
+
+// SendTxn sends a txnkv request.
+func (t *GRPCTransport) SendTxn(route string, req *TxnRequest) (*TxnResponse, error) {
+	method, id, err := t.parseRoute(route)
+	if err != nil {
+		return nil, err
+	}
+	in := &proxypb.TxnRequest{
+		Id:              id,
+		PdAddrs:         req.PDAddrs,
+		Ts:              req.TS,
+		Key:             req.Key,
+		Value:           req.Value,
+		Keys:            req.Keys,
+		UpperBound:      req.UpperBound,
+		Pessimistic:     req.Pessimistic,
+		AsyncCommit:     req.AsyncCommit,
+		OnePc:           req.OnePC,
+		LockWaitTimeout: req.LockWaitTimeout,
+		EntrySizeLimit:  req.EntrySizeLimit,
+		TotalSizeLimit:  req.TotalSizeLimit,
+	}
+	var out proxypb.TxnResponse
+	if err = t.invoke(method, in, &out); err != nil {
+		return nil, err
+	}
+	return &TxnResponse{
+		ID:         out.Id,
+		TS:         out.Ts,
+		Key:        out.Key,
+		Value:      out.Value,
+		Keys:       out.Keys,
+		Values:     out.Values,
+		IsValid:    out.IsValid,
+		IsReadOnly: out.IsReadonly,
+		Size:       int(out.Size),
+		Length:     int(out.Length),
+	}, nil
+}
+
+// GetClientInfo queries the identity of the client behind the proxy server.
+func (t *GRPCTransport) GetClientInfo() (*ClientInfo, error) {
+	var out proxypb.ClientInfo
+	if err := t.invoke(proxypb.Proxy_Info_FullMethodName, &proxypb.InfoRequest{}, &out); err != nil {
+		return nil, err
+	}
+	return &ClientInfo{
+		Name:         out.Name,
+		Language:     out.Language,
+		Version:      out.Version,
+		GitCommit:    out.GitCommit,
+		Capabilities: out.Capabilities,
+	}, nil
+}
+
+// Close releases the connection to the proxy server.
+func (t *GRPCTransport) Close() error {
+	return errors.WithStack(t.conn.Close())
+}
+
+// parseRoute returns the grpc method and the id in the route.
+func (t *GRPCTransport) parseRoute(route string) (method string, id string, err error) {
+	parts := strings.Split(strings.TrimPrefix(route, "/"), "/")
+	if len(parts) == 4 {
+		id = parts[2]
+		parts[2] = "{id}"
+	}
+	method, ok := grpcMethods["/"+strings.Join(parts, "/")]
+	if !ok {
+		return "", "", errors.Errorf("no grpc method for route %s", route)
+	}
+	return method, id, nil
+}
+
+func (t *GRPCTransport) invoke(method string, in, out proto.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+	defer cancel()
+	err := t.conn.Invoke(ctx, method, in, out)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Wrap(ErrCallTimeout, err.Error())
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return errors.WithStack(err)
+	}
+	// Keep errors the same as the http transport, so tests can check them by
+	// messages regardless of the transport.
+	switch s.Code() {
+	case codes.Unimplemented:
+		return errors.Errorf("%s: not implemented", method)
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return errors.WithStack(err)
+	default:
+		return errors.WithStack(&StatusError{Status: s.Code().String(), Message: s.Message()})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stub
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tikv/client-validator/proxypb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCParseRoute(t *testing.T) {
+	var transport GRPCTransport
+	for route, expect := range grpcMethods {
+		id := ""
+		if strings.Contains(route, "{id}") {
+			id = "42"
+		}
+		method, gotID, err := transport.parseRoute(strings.Replace(route, "{id}", id, 1))
+		if err != nil || method != expect || gotID != id {
+			t.Fatalf("route %s: expect %s %q, got %s %q %v", route, expect, id, method, gotID, err)
+		}
+	}
+	for _, route := range []string{"/rawkv/client/42/unknown", "/rawkv/new", "/unknown/client/42/get"} {
+		if _, _, err := transport.parseRoute(route); err == nil {
+			t.Fatalf("route %s: expect error", route)
+		}
+	}
+}
+
+// grpcRawServer answers Get by keys: "v" returns a value, "slow" hangs, other
+// keys are returned as the error code. Other methods are not implemented.
+type grpcRawServer struct {
+	proxypb.UnimplementedRawKVServer
+}
+
+func (grpcRawServer) Get(ctx context.Context, req *proxypb.RawRequest) (*proxypb.RawResponse, error) {
+	switch key := string(req.Key); key {
+	case "v":
+		return &proxypb.RawResponse{Value: []byte("value of " + req.Id)}, nil
+	case "slow":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	case "unavailable":
+		return nil, status.Error(codes.Unavailable, "proxy is unavailable")
+	default:
+		return nil, status.Error(codes.NotFound, "client "+req.Id+" is not found")
+	}
+}
+
+func TestGRPCTransport(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	proxypb.RegisterRawKVServer(server, grpcRawServer{})
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := &GRPCTransport{conn: conn, timeout: 200 * time.Millisecond}
+	defer transport.Close()
+
+	get := func(key string) (*RawResponse, error) {
+		return transport.SendRaw("/rawkv/client/42/get", &RawRequest{Key: []byte(key)})
+	}
+	if resp, err := get("v"); err != nil || string(resp.Value) != "value of 42" {
+		t.Fatalf("expect value of 42, got %+v %v", resp, err)
+	}
+
+	// Errors are the same as the http transport.
+	_, err = get("notfound")
+	if statusErr, ok := errors.Cause(err).(*StatusError); !ok || statusErr.Status != codes.NotFound.String() || statusErr.Message != "client 42 is not found" {
+		t.Fatalf("expect status error, got %v", err)
+	}
+	_, err = transport.SendRaw("/rawkv/client/42/put", &RawRequest{Key: []byte("k")})
+	if err == nil || !strings.HasSuffix(err.Error(), "not implemented") {
+		t.Fatalf("expect not implemented error, got %v", err)
+	}
+	_, err = get("unavailable")
+	if s, ok := status.FromError(errors.Cause(err)); !ok || s.Code() != codes.Unavailable {
+		t.Fatalf("expect unavailable error, got %v", err)
+	}
+	_, err = get("slow")
+	if errors.Cause(err) != ErrCallTimeout {
+		t.Fatalf("expect call timeout, got %v", err)
+	}
+}
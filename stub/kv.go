@@ -0,0 +1,139 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stub
+
+import "time"
+
+// RawKV is a rawkv client under validation. It is implemented by stubs which
+// redirect calls to a proxy server, and by adapters which call a client
+// in-process.
+type RawKV interface {
+	// Close closes the client and releases resources.
+	Close() error
+	// WithCF returns a view of the client whose operations access the column
+	// family cf, the client itself is not changed. The default column family
+	// is used if cf is empty. Closing the view does nothing, the client should
+	// be closed instead.
+	WithCF(cf string) RawKV
+	// Get queries value with the key.
+	Get(key []byte) ([]byte, error)
+	// BatchGet queries values with the keys.
+	BatchGet(keys [][]byte) ([][]byte, error)
+	// Put stores a key-value pair.
+	Put(key, value []byte) error
+	// BatchPut stores key-value pairs.
+	BatchPut(keys, values [][]byte) error
+	// PutWithTTL stores a key-value pair which expires after ttl seconds.
+	PutWithTTL(key, value []byte, ttl uint64) error
+	// BatchPutWithTTL stores key-value pairs, each expires after its ttl.
+	BatchPutWithTTL(keys, values [][]byte, ttls []uint64) error
+	// GetKeyTTL returns the remaining TTL (in seconds) of the key, nil if the
+	// key does not exist.
+	GetKeyTTL(key []byte) (*uint64, error)
+	// SetAtomicForCAS toggles the atomic mode, which is required by
+	// CompareAndSwap.
+	SetAtomicForCAS(atomic bool) error
+	// CompareAndSwap sets the key to newValue if its current value is
+	// previousValue, or it does not exist if previousNotExist is true. It
+	// returns the previous value and whether the swap succeeded.
+	CompareAndSwap(key, previousValue, newValue []byte, previousNotExist bool) ([]byte, bool, error)
+	// Delete deletes a key-value pair.
+	Delete(key []byte) error
+	// BatchDelete deletes key-value pairs.
+	BatchDelete(keys [][]byte) error
+	// DeleteRange deletes all key-value pairs in range [startKey, endKey).
+	DeleteRange(startKey, endKey []byte) error
+	// Scan queries continuous kv pairs in range [startKey, endKey), up to
+	// limit pairs.
+	Scan(startKey, endKey []byte, limit int) ([][]byte, [][]byte, error)
+	// ReverseScan queries continuous kv pairs in range [endKey, startKey) from
+	// startKey to endKey, up to limit pairs.
+	ReverseScan(startKey, endKey []byte, limit int) ([][]byte, [][]byte, error)
+	// ScanKeyOnly queries continuous keys in range [startKey, endKey), up to
+	// limit keys. Values returned by the client should be empty.
+	ScanKeyOnly(startKey, endKey []byte, limit int) ([][]byte, [][]byte, error)
+}
+
+// TxnKV is a txnkv client under validation.
+type TxnKV interface {
+	// Close closes the client and releases resources.
+	Close() error
+	// Begin creates a transaction for read/write.
+	Begin() (Transaction, error)
+	// BeginPessimistic creates a pessimistic transaction for read/write.
+	BeginPessimistic() (Transaction, error)
+	// BeginWithOptions creates a transaction for read/write with options.
+	BeginWithOptions(opts TxnOptions) (Transaction, error)
+	// BeginWithTS creates a transaction which is normally readonly.
+	BeginWithTS(ts uint64) (Transaction, error)
+	// GetTS returns a latest timestamp.
+	GetTS() (uint64, error)
+}
+
+// Transaction is a transaction of a txnkv client.
+type Transaction interface {
+	// Get retrives the value for the given key.
+	Get(k []byte) ([]byte, error)
+	// BatchGet gets a batch of values, keys not found are absent in the map.
+	BatchGet(keys [][]byte) (map[string][]byte, error)
+	// Set sets the value for key k as v.
+	Set(k []byte, v []byte) error
+	// Delete removes the entry for key k.
+	Delete(k []byte) error
+	// Iter creates an Iterator positioned on the first entry that k <= entry's
+	// key, and iterates keys less than upperBound.
+	Iter(k []byte, upperBound []byte) (Iterator, error)
+	// IterReverse creates a reversed Iterator positioned on the first entry
+	// which key is less than k.
+	IterReverse(k []byte) (Iterator, error)
+	// IsReadOnly returns if there are no pending key-value to commit.
+	IsReadOnly() (bool, error)
+	// Commit commits the transaction operations.
+	Commit() error
+	// Rollback undoes the transaction operations.
+	Rollback() error
+	// SetLockWaitTimeout sets how long LockKeys waits for locks held by other
+	// transactions. Zero means the client's default, negative means no wait.
+	SetLockWaitTimeout(timeout time.Duration)
+	// LockKeys tries to lock the entries with the keys.
+	LockKeys(keys ...[]byte) error
+	// Valid returns if the transaction is valid. A transaction becomes invalid
+	// after commit or rollback.
+	Valid() (bool, error)
+	// Len returns the count of key-value pairs in the memory buffer.
+	Len() (int, error)
+	// Size returns the length (in bytes) of the memory buffer.
+	Size() (int, error)
+}
+
+// Iterator iterates key-value pairs of a transaction.
+type Iterator interface {
+	// Valid returns if the iterator is valid to use.
+	Valid() (bool, error)
+	// Key returns the key the iterator currently positioned at.
+	Key() ([]byte, error)
+	// Value returns the value the iterator currently positioned at.
+	Value() ([]byte, error)
+	// Next moves the iterator to next position.
+	Next() error
+	// Close releases the iterator.
+	Close() error
+}
+
+var (
+	_ RawKV       = (*RawClientStub)(nil)
+	_ TxnKV       = (*TxnClientStub)(nil)
+	_ Transaction = (*TransactionStub)(nil)
+	_ Iterator    = (*IteratorStub)(nil)
+)
@@ -21,9 +21,18 @@ type RawRequest struct {
 	Keys     [][]byte `json:"keys,omitempty"`      // for batchGet, batchPut, batchDelete
 	Value    []byte   `json:"value,omitempty"`     // for put
 	Values   [][]byte `json:"values,omitmepty"`    // for batchPut
+	TTL      uint64   `json:"ttl,omitempty"`       // for put, in seconds
+	TTLs     []uint64 `json:"ttls,omitempty"`      // for batchPut, in seconds
 	StartKey []byte   `json:"start_key,omitempty"` // for scan, deleteRange
 	EndKey   []byte   `json:"end_key,omitempty"`   // for scan, deleteRange
 	Limit    int      `json:"limit,omitempty"`     // for scan
+	Reverse  bool     `json:"reverse,omitempty"`   // for scan
+	KeyOnly  bool     `json:"key_only,omitempty"`  // for scan
+	CF       string   `json:"cf,omitempty"`        // for all operations on data, empty means default
+
+	PreviousValue    []byte `json:"previous_value,omitempty"`     // for compareAndSwap
+	PreviousNotExist bool   `json:"previous_not_exist,omitempty"` // for compareAndSwap
+	Atomic           bool   `json:"atomic,omitempty"`             // for setAtomicForCAS
 }
 
 // RawResponse is the structure of a rawkv response that the http proxy sends.
@@ -33,6 +42,11 @@ type RawResponse struct {
 	Value  []byte   `json:"value,omitempty"`  // for get
 	Keys   [][]byte `json:"keys,omitempty"`   // for scan
 	Values [][]byte `json:"values,omitempty"` // for batchGet
+	TTL    *uint64  `json:"ttl,omitempty"`    // for getKeyTTL, nil if key does not exist
+
+	PreviousValue    []byte `json:"previous_value,omitempty"`     // for compareAndSwap
+	PreviousNotExist bool   `json:"previous_not_exist,omitempty"` // for compareAndSwap
+	Succeed          bool   `json:"succeed,omitempty"`            // for compareAndSwap
 }
 
 // TxnRequest is the structure of a txnkv request that the http proxy accepts.
@@ -44,6 +58,14 @@ type TxnRequest struct {
 	Value      []byte   `json:"value,omitempty"`       // for set
 	Keys       [][]byte `json:"keys,omitempty"`        // for batchGet, lockKeys
 	UpperBound []byte   `json:"upper_bound,omitempty"` // for iter
+
+	Pessimistic     bool  `json:"pessimistic,omitempty"`       // for begin
+	AsyncCommit     bool  `json:"async_commit,omitempty"`      // for begin
+	OnePC           bool  `json:"one_pc,omitempty"`            // for begin
+	LockWaitTimeout int64 `json:"lock_wait_timeout,omitempty"` // for lockKeys, in milliseconds, 0 means default, negative means no wait
+
+	EntrySizeLimit uint64 `json:"entry_size_limit,omitempty"` // for begin, in bytes, 0 means default
+	TotalSizeLimit uint64 `json:"total_size_limit,omitempty"` // for begin, in bytes, 0 means default
 }
 
 // TxnResponse is the structure of a txnkv response that the http proxy sends.
@@ -60,3 +82,13 @@ type TxnResponse struct {
 	Size       int      `json:"size,omitempty"`        // for size
 	Length     int      `json:"length,omitempty"`      // for length
 }
+
+// ClientInfo is the structure of the client identity that the http proxy
+// reports. It should be kept synced with the proxy server.
+type ClientInfo struct {
+	Name         string   `json:"name,omitempty"`
+	Language     string   `json:"language,omitempty"`
+	Version      string   `json:"version,omitempty"`
+	GitCommit    string   `json:"git_commit,omitempty"`
+	Capabilities []string `json:"capabilities,omitempty"` // keys of features the client claims to support
+}
@@ -14,31 +14,27 @@
 package stub
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"strings"
-	"time"
-
-	"github.com/pkg/errors"
 )
 
 // RawClientStub can be used like a rawkv.Client while it redirects all function
-// calls to an httpproxy server.
+// calls to a proxy server.
 type RawClientStub struct {
-	client      http.Client
-	proxyServer string
-	id          string
+	transport Transport
+	id        string
+	cf        string
+	view      bool // returned by WithCF, it does not own the client
 }
 
-// NewRawClientStub creates a client for rawkv calls.
+// NewRawClientStub creates a client for rawkv calls to a proxy server.
 func NewRawClientStub(proxyServer string, pdServers []string) (*RawClientStub, error) {
-	client := &RawClientStub{
-		client:      http.Client{Timeout: time.Second * 10},
-		proxyServer: strings.TrimSuffix(proxyServer, "/"),
-	}
+	return NewRawClientStubWithTransport(NewHTTPTransport(proxyServer), pdServers)
+}
+
+// NewRawClientStubWithTransport creates a client for rawkv calls which are
+// sent by the transport.
+func NewRawClientStubWithTransport(transport Transport, pdServers []string) (*RawClientStub, error) {
+	client := &RawClientStub{transport: transport}
 	res, err := client.send("/rawkv/client/new", &RawRequest{PDAddrs: pdServers})
 	if err != nil {
 		return nil, err
@@ -47,12 +43,25 @@ func NewRawClientStub(proxyServer string, pdServers []string) (*RawClientStub, e
 	return client, nil
 }
 
-// Close closes the client and releases resources in proxy server.
+// Close closes the client and releases resources in proxy server. It does
+// nothing on a view returned by WithCF.
 func (c *RawClientStub) Close() error {
+	if c.view {
+		return nil
+	}
 	_, err := c.send(fmt.Sprintf("/rawkv/client/%s/close", c.id), &RawRequest{})
 	return err
 }
 
+// WithCF returns a view of the client whose requests access the column family
+// cf. The default column family is used if cf is empty. Closing the view does
+// nothing.
+func (c *RawClientStub) WithCF(cf string) RawKV {
+	view := *c
+	view.cf, view.view = cf, true
+	return &view
+}
+
 // Get queries value with the key. When the key does not exist, it returns `nil, nil`.
 func (c *RawClientStub) Get(key []byte) ([]byte, error) {
 	res, err := c.send(fmt.Sprintf("/rawkv/client/%s/get", c.id), &RawRequest{Key: key})
@@ -83,6 +92,58 @@ func (c *RawClientStub) BatchPut(keys, values [][]byte) error {
 	return err
 }
 
+// PutWithTTL stores a key-value pair to TiKV, which expires after ttl seconds.
+// A zero ttl means the pair never expires.
+func (c *RawClientStub) PutWithTTL(key, value []byte, ttl uint64) error {
+	_, err := c.send(fmt.Sprintf("/rawkv/client/%s/put", c.id), &RawRequest{Key: key, Value: value, TTL: ttl})
+	return err
+}
+
+// BatchPutWithTTL stores key-value pairs to TiKV, each pair expires after the
+// corresponding ttl seconds.
+func (c *RawClientStub) BatchPutWithTTL(keys, values [][]byte, ttls []uint64) error {
+	_, err := c.send(fmt.Sprintf("/rawkv/client/%s/batch-put", c.id), &RawRequest{Keys: keys, Values: values, TTLs: ttls})
+	return err
+}
+
+// GetKeyTTL queries the remaining TTL (in seconds) of the key. When the key does
+// not exist, it returns `nil, nil`. When the key never expires, it returns 0.
+func (c *RawClientStub) GetKeyTTL(key []byte) (*uint64, error) {
+	res, err := c.send(fmt.Sprintf("/rawkv/client/%s/get-key-ttl", c.id), &RawRequest{Key: key})
+	if err != nil {
+		return nil, err
+	}
+	return res.TTL, nil
+}
+
+// SetAtomicForCAS sets the client to atomic mode, which is required by
+// CompareAndSwap. In atomic mode, all write operations of the client are
+// serialized with CAS operations.
+func (c *RawClientStub) SetAtomicForCAS(atomic bool) error {
+	_, err := c.send(fmt.Sprintf("/rawkv/client/%s/set-atomic-for-cas", c.id), &RawRequest{Atomic: atomic})
+	return err
+}
+
+// CompareAndSwap stores newValue to the key if its current value equals to
+// previousValue, or the key does not exist when previousNotExist is true.
+// It returns the value before the operation (nil if the key does not exist)
+// and whether the swap succeeded.
+func (c *RawClientStub) CompareAndSwap(key, previousValue, newValue []byte, previousNotExist bool) ([]byte, bool, error) {
+	res, err := c.send(fmt.Sprintf("/rawkv/client/%s/compare-and-swap", c.id), &RawRequest{
+		Key:              key,
+		Value:            newValue,
+		PreviousValue:    previousValue,
+		PreviousNotExist: previousNotExist,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if res.PreviousNotExist {
+		return nil, res.Succeed, nil
+	}
+	return res.PreviousValue, res.Succeed, nil
+}
+
 // Delete deletes a key-value pair from TiKV.
 func (c *RawClientStub) Delete(key []byte) error {
 	_, err := c.send(fmt.Sprintf("/rawkv/client/%s/delete", c.id), &RawRequest{Key: key})
@@ -110,29 +171,27 @@ func (c *RawClientStub) Scan(startKey, endKey []byte, limit int) ([][]byte, [][]
 	return res.Keys, res.Values, nil
 }
 
-func (c *RawClientStub) send(uri string, req *RawRequest) (*RawResponse, error) {
-	b, err := json.Marshal(req)
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-	res, err := c.client.Post(c.proxyServer+uri, "application/json", bytes.NewReader(b))
+// ReverseScan queries continuous kv pairs in range [endKey, startKey), up to
+// limit pairs. The direction is different from Scan, from upper to lower.
+func (c *RawClientStub) ReverseScan(startKey, endKey []byte, limit int) ([][]byte, [][]byte, error) {
+	res, err := c.send(fmt.Sprintf("/rawkv/client/%s/scan", c.id), &RawRequest{StartKey: startKey, EndKey: endKey, Limit: limit, Reverse: true})
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, nil, err
 	}
-	defer res.Body.Close()
-	body, err := ioutil.ReadAll(res.Body)
+	return res.Keys, res.Values, nil
+}
+
+// ScanKeyOnly queries continuous keys in range [startKey, endKey), up to limit
+// keys. Values returned by the client should be empty.
+func (c *RawClientStub) ScanKeyOnly(startKey, endKey []byte, limit int) ([][]byte, [][]byte, error) {
+	res, err := c.send(fmt.Sprintf("/rawkv/client/%s/scan", c.id), &RawRequest{StartKey: startKey, EndKey: endKey, Limit: limit, KeyOnly: true})
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, nil, err
 	}
+	return res.Keys, res.Values, nil
+}
 
-	switch {
-	case res.StatusCode >= 200 && res.StatusCode < 300: // 2xx means OK.
-		var resp RawResponse
-		if err = json.Unmarshal(body, &resp); err != nil {
-			return nil, errors.WithStack(err)
-		}
-		return &resp, nil
-	default:
-		return nil, errors.New(string(body))
-	}
+func (c *RawClientStub) send(uri string, req *RawRequest) (*RawResponse, error) {
+	req.CF = c.cf
+	return c.transport.SendRaw(uri, req)
 }
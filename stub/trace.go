@@ -0,0 +1,197 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stub
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TraceLevel controls how much of a call is traced.
+type TraceLevel int
+
+// Trace levels.
+const (
+	// TraceNone traces nothing.
+	TraceNone TraceLevel = iota
+	// TraceErrors traces failed calls only, with requests and errors.
+	TraceErrors
+	// TraceCalls traces route, status and latency of all calls.
+	TraceCalls
+	// TraceFull traces all calls with requests and responses.
+	TraceFull
+)
+
+// ParseTraceLevel parses a trace level by name: none | errors | calls | full.
+func ParseTraceLevel(s string) (TraceLevel, error) {
+	switch s {
+	case "none":
+		return TraceNone, nil
+	case "errors":
+		return TraceErrors, nil
+	case "calls":
+		return TraceCalls, nil
+	case "full":
+		return TraceFull, nil
+	default:
+		return TraceNone, errors.Errorf("unknown trace level: %s", s)
+	}
+}
+
+const (
+	// traceMaxBytes is the max length of a key or value in a trace, longer ones
+	// are truncated.
+	traceMaxBytes = 64
+	// traceMaxItems is the max count of keys or values in a trace.
+	traceMaxItems = 16
+)
+
+// StatusError is an error returned by a proxy server with the status of the
+// response, e.g. "500 Internal Server Error" for http or "Unknown" for grpc.
+type StatusError struct {
+	Status  string
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return e.Message
+}
+
+// TraceTransport wraps a Transport and reports every call by a function.
+type TraceTransport struct {
+	Transport
+	level   TraceLevel
+	enabled func() bool
+	trace   func(string)
+}
+
+// NewTraceTransport creates a TraceTransport which reports calls to trace.
+// Calls are not formatted while enabled returns false, e.g. when a benchmark
+// is measuring them. A nil enabled means always enabled.
+func NewTraceTransport(transport Transport, level TraceLevel, enabled func() bool, trace func(msg string)) *TraceTransport {
+	return &TraceTransport{
+		Transport: transport,
+		level:     level,
+		enabled:   enabled,
+		trace:     trace,
+	}
+}
+
+// SendRaw sends a rawkv request and traces it.
+func (t *TraceTransport) SendRaw(route string, req *RawRequest) (*RawResponse, error) {
+	start := time.Now()
+	resp, err := t.Transport.SendRaw(route, req)
+	t.record(route, req, resp, err, time.Since(start))
+	return resp, err
+}
+
+// SendTxn sends a txnkv request and traces it.
+func (t *TraceTransport) SendTxn(route string, req *TxnRequest) (*TxnResponse, error) {
+	start := time.Now()
+	resp, err := t.Transport.SendTxn(route, req)
+	t.record(route, req, resp, err, time.Since(start))
+	return resp, err
+}
+
+func (t *TraceTransport) record(route string, req, resp interface{}, err error, latency time.Duration) {
+	if t.level == TraceNone || (t.level == TraceErrors && err == nil) || (t.enabled != nil && !t.enabled()) {
+		return
+	}
+	status := "OK"
+	if err != nil {
+		status = "ERROR"
+		if se, ok := errors.Cause(err).(*StatusError); ok {
+			status = se.Status
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "call %s [%s] %v", route, status, latency.Round(time.Microsecond))
+	if t.level != TraceCalls {
+		b.WriteString(" req=")
+		b.WriteString(formatMessage(req))
+		if err != nil {
+			b.WriteString(" err=")
+			b.WriteString(strconv.Quote(err.Error()))
+		} else {
+			b.WriteString(" resp=")
+			b.WriteString(formatMessage(resp))
+		}
+	}
+	t.trace(b.String())
+}
+
+// formatMessage formats a request or response like json, but keys and values
+// are printed as quoted strings instead of base64. Empty fields are omitted,
+// long keys, values and lists are truncated.
+func formatMessage(msg interface{}) string {
+	v := reflect.Indirect(reflect.ValueOf(msg))
+	if !v.IsValid() {
+		return "null"
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if f.IsZero() {
+			continue
+		}
+		name := strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0]
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Quote(name))
+		b.WriteByte(':')
+		formatValue(&b, reflect.Indirect(f))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatValue(b *strings.Builder, v reflect.Value) {
+	switch {
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		formatBytes(b, v.Bytes())
+	case v.Kind() == reflect.Slice:
+		b.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if i == traceMaxItems {
+				fmt.Fprintf(b, "...(%d items)", v.Len())
+				break
+			}
+			formatValue(b, v.Index(i))
+		}
+		b.WriteByte(']')
+	case v.Kind() == reflect.String:
+		b.WriteString(strconv.Quote(v.String()))
+	default:
+		fmt.Fprint(b, v.Interface())
+	}
+}
+
+func formatBytes(b *strings.Builder, data []byte) {
+	if len(data) <= traceMaxBytes {
+		b.WriteString(strconv.Quote(string(data)))
+		return
+	}
+	b.WriteString(strconv.Quote(string(data[:traceMaxBytes])))
+	fmt.Fprintf(b, "...(%d bytes)", len(data))
+}
@@ -0,0 +1,150 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stub
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Transport sends requests of stubs to a proxy server. Requests are identified
+// by routes of the http proxy, e.g. "/rawkv/client/{id}/get".
+type Transport interface {
+	SendRaw(route string, req *RawRequest) (*RawResponse, error)
+	SendTxn(route string, req *TxnRequest) (*TxnResponse, error)
+	// GetClientInfo queries the identity of the client behind the proxy server.
+	GetClientInfo() (*ClientInfo, error)
+	// Close releases the connection to the proxy server.
+	Close() error
+}
+
+// CallTimeout bounds each call to a client, through a proxy server or
+// in-process. It should be set before clients are created.
+var CallTimeout = time.Second * 10
+
+// ErrCallTimeout is the cause of errors returned when a call to a client does
+// not return in CallTimeout. It tells a hanging client from a client returning
+// errors in time.
+var ErrCallTimeout = errors.New("call to the client timed out")
+
+// Transport kinds.
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+// NewTransport creates a transport of the kind to the proxy server.
+func NewTransport(kind string, proxyServer string) (Transport, error) {
+	switch kind {
+	case TransportHTTP:
+		return NewHTTPTransport(proxyServer), nil
+	case TransportGRPC:
+		return NewGRPCTransport(proxyServer)
+	default:
+		return nil, errors.Errorf("unknown proxy transport: %s", kind)
+	}
+}
+
+// HTTPTransport sends requests as json to an httpproxy server.
+type HTTPTransport struct {
+	client      http.Client
+	proxyServer string
+}
+
+// NewHTTPTransport creates a transport to an httpproxy server.
+func NewHTTPTransport(proxyServer string) *HTTPTransport {
+	return &HTTPTransport{
+		client:      http.Client{Timeout: CallTimeout},
+		proxyServer: strings.TrimSuffix(proxyServer, "/"),
+	}
+}
+
+// SendRaw sends a rawkv request.
+func (t *HTTPTransport) SendRaw(route string, req *RawRequest) (*RawResponse, error) {
+	var resp RawResponse
+	if err := t.post(route, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SendTxn sends a txnkv request.
+func (t *HTTPTransport) SendTxn(route string, req *TxnRequest) (*TxnResponse, error) {
+	var resp TxnResponse
+	if err := t.post(route, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetClientInfo queries the identity of the client behind the proxy server.
+func (t *HTTPTransport) GetClientInfo() (*ClientInfo, error) {
+	res, err := t.client.Get(t.proxyServer + "/info")
+	if err != nil {
+		return nil, httpErr(err)
+	}
+	var info ClientInfo
+	if err = t.readResponse(res, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Close releases the connection to the proxy server.
+func (t *HTTPTransport) Close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+func (t *HTTPTransport) post(route string, req interface{}, resp interface{}) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	res, err := t.client.Post(t.proxyServer+route, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return httpErr(err)
+	}
+	return t.readResponse(res, resp)
+}
+
+func (t *HTTPTransport) readResponse(res *http.Response, resp interface{}) error {
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300: // 2xx means OK.
+		return errors.WithStack(json.Unmarshal(body, resp))
+	default:
+		return errors.WithStack(&StatusError{Status: res.Status, Message: string(body)})
+	}
+}
+
+// httpErr marks err as ErrCallTimeout if the request ran out of CallTimeout.
+func httpErr(err error) error {
+	if e, ok := err.(net.Error); ok && e.Timeout() {
+		return errors.Wrap(ErrCallTimeout, err.Error())
+	}
+	return errors.WithStack(err)
+}
@@ -14,31 +14,26 @@
 package stub
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"strings"
 	"time"
-
-	"github.com/pkg/errors"
 )
 
 // TxnClientStub can be used like a txnkv.Client while it redirects all function
-// calls to an httpproxy server.
+// calls to a proxy server.
 type TxnClientStub struct {
-	client      http.Client
-	proxyServer string
-	id          string
+	transport Transport
+	id        string
 }
 
-// NewTxnClientStub creates a client for txnkv calls.
+// NewTxnClientStub creates a client for txnkv calls to a proxy server.
 func NewTxnClientStub(proxyServer string, pdServers []string) (*TxnClientStub, error) {
-	client := &TxnClientStub{
-		client:      http.Client{Timeout: time.Second * 10},
-		proxyServer: strings.TrimSuffix(proxyServer, "/"),
-	}
+	return NewTxnClientStubWithTransport(NewHTTPTransport(proxyServer), pdServers)
+}
+
+// NewTxnClientStubWithTransport creates a client for txnkv calls which are
+// sent by the transport.
+func NewTxnClientStubWithTransport(transport Transport, pdServers []string) (*TxnClientStub, error) {
+	client := &TxnClientStub{transport: transport}
 	res, err := client.send("/txnkv/client/new", &TxnRequest{PDAddrs: pdServers})
 	if err != nil {
 		return nil, err
@@ -53,9 +48,42 @@ func (c *TxnClientStub) Close() error {
 	return err
 }
 
+// TxnOptions are options to begin a transaction.
+type TxnOptions struct {
+	// Pessimistic locks keys by LockKeys before commit, so the commit does not
+	// fail for write conflicts.
+	Pessimistic bool
+	// AsyncCommit makes the transaction committed once all keys are prewritten.
+	AsyncCommit bool
+	// OnePC commits the transaction in one phase if all keys are in one region.
+	OnePC bool
+	// EntrySizeLimit is the max size (in bytes) of a key-value entry, 0 means
+	// the client's default.
+	EntrySizeLimit uint64
+	// TotalSizeLimit is the max size (in bytes) of all entries in the
+	// transaction, 0 means the client's default.
+	TotalSizeLimit uint64
+}
+
 // Begin creates a transaction for read/write.
-func (c *TxnClientStub) Begin() (*TransactionStub, error) {
-	res, err := c.send(fmt.Sprintf("/txnkv/client/%s/begin", c.id), &TxnRequest{})
+func (c *TxnClientStub) Begin() (Transaction, error) {
+	return c.BeginWithOptions(TxnOptions{})
+}
+
+// BeginPessimistic creates a pessimistic transaction for read/write.
+func (c *TxnClientStub) BeginPessimistic() (Transaction, error) {
+	return c.BeginWithOptions(TxnOptions{Pessimistic: true})
+}
+
+// BeginWithOptions creates a transaction for read/write with options.
+func (c *TxnClientStub) BeginWithOptions(opts TxnOptions) (Transaction, error) {
+	res, err := c.send(fmt.Sprintf("/txnkv/client/%s/begin", c.id), &TxnRequest{
+		Pessimistic:    opts.Pessimistic,
+		AsyncCommit:    opts.AsyncCommit,
+		OnePC:          opts.OnePC,
+		EntrySizeLimit: opts.EntrySizeLimit,
+		TotalSizeLimit: opts.TotalSizeLimit,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +94,7 @@ func (c *TxnClientStub) Begin() (*TransactionStub, error) {
 }
 
 // BeginWithTS creates a transaction which is normally readonly.
-func (c *TxnClientStub) BeginWithTS(ts uint64) (*TransactionStub, error) {
+func (c *TxnClientStub) BeginWithTS(ts uint64) (Transaction, error) {
 	res, err := c.send(fmt.Sprintf("/txnkv/client/%s/begin-with-ts", c.id), &TxnRequest{TS: ts})
 	if err != nil {
 		return nil, err
@@ -87,10 +115,11 @@ func (c *TxnClientStub) GetTS() (uint64, error) {
 }
 
 // TransactionStub can be used like a txnkv.Trasaction while it redirects all
-// function calls to an httpproxy server.
+// function calls to a proxy server.
 type TransactionStub struct {
-	client *TxnClientStub
-	id     string
+	client          *TxnClientStub
+	id              string
+	lockWaitTimeout time.Duration
 }
 
 func (txn *TransactionStub) String() string {
@@ -126,7 +155,7 @@ func (txn *TransactionStub) Set(k []byte, v []byte) error {
 }
 
 // Iter creates an Iterator positioned on the first entry that k <= entry's key.
-func (txn *TransactionStub) Iter(k []byte, upperBound []byte) (*IteratorStub, error) {
+func (txn *TransactionStub) Iter(k []byte, upperBound []byte) (Iterator, error) {
 	res, err := txn.client.send(fmt.Sprintf("/txnkv/txn/%s/iter", txn.id), &TxnRequest{Key: k, UpperBound: upperBound})
 	if err != nil {
 		return nil, err
@@ -138,7 +167,7 @@ func (txn *TransactionStub) Iter(k []byte, upperBound []byte) (*IteratorStub, er
 }
 
 // IterReverse creates a reversed Iterator positioned on the first entry which key is less than k.
-func (txn *TransactionStub) IterReverse(k []byte) (*IteratorStub, error) {
+func (txn *TransactionStub) IterReverse(k []byte) (Iterator, error) {
 	res, err := txn.client.send(fmt.Sprintf("/txnkv/txn/%s/iter-reverse", txn.id), &TxnRequest{Key: k})
 	if err != nil {
 		return nil, err
@@ -176,9 +205,22 @@ func (txn *TransactionStub) Rollback() error {
 	return err
 }
 
+// SetLockWaitTimeout sets how long LockKeys waits for locks held by other
+// transactions. Zero means the client's default, negative means no wait.
+func (txn *TransactionStub) SetLockWaitTimeout(timeout time.Duration) {
+	txn.lockWaitTimeout = timeout
+}
+
 // LockKeys tries to lock the entries with the keys.
 func (txn *TransactionStub) LockKeys(keys ...[]byte) error {
-	_, err := txn.client.send(fmt.Sprintf("/txnkv/txn/%s/lock-keys", txn.id), &TxnRequest{Keys: keys})
+	req := &TxnRequest{Keys: keys}
+	switch {
+	case txn.lockWaitTimeout < 0:
+		req.LockWaitTimeout = -1
+	case txn.lockWaitTimeout > 0:
+		req.LockWaitTimeout = int64(txn.lockWaitTimeout / time.Millisecond)
+	}
+	_, err := txn.client.send(fmt.Sprintf("/txnkv/txn/%s/lock-keys", txn.id), req)
 	return err
 }
 
@@ -211,7 +253,7 @@ func (txn *TransactionStub) Size() (int, error) {
 }
 
 // IteratorStub can be used like a txnkv.kv.Iterator while it redirects all
-// function calls to an httpproxy server.
+// function calls to a proxy server.
 type IteratorStub struct {
 	client *TxnClientStub
 	id     string
@@ -221,7 +263,7 @@ type IteratorStub struct {
 func (iter *IteratorStub) Valid() (bool, error) {
 	res, err := iter.client.send(fmt.Sprintf("/txnkv/iter/%s/valid", iter.id), &TxnRequest{})
 	if err != nil {
-		return false, nil
+		return false, err
 	}
 	return res.IsValid, nil
 }
@@ -257,28 +299,5 @@ func (iter *IteratorStub) Close() error {
 }
 
 func (c *TxnClientStub) send(uri string, req *TxnRequest) (*TxnResponse, error) {
-	b, err := json.Marshal(req)
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-	res, err := c.client.Post(c.proxyServer+uri, "application/json", bytes.NewReader(b))
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-	defer res.Body.Close()
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-
-	switch {
-	case res.StatusCode >= 200 && res.StatusCode < 300: // 2xx means OK.
-		var resp TxnResponse
-		if err = json.Unmarshal(body, &resp); err != nil {
-			return nil, errors.WithStack(err)
-		}
-		return &resp, nil
-	default:
-		return nil, errors.New(string(body))
-	}
+	return c.transport.SendTxn(uri, req)
 }
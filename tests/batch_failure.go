@@ -0,0 +1,167 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tikv/client-validator/mocktikv"
+	"github.com/tikv/client-validator/validator"
+)
+
+var _ = validator.RegisterFeature("rawkv.batch-partial-failure", "report errors of batch writes failing in some regions", []string{"rawkv.batch-put"}, testRawKV{}.checkBatchPartialFailure)
+
+func (t testRawKV) checkBatchPartialFailure(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	mustSplit(ctx, cluster, "k2", "")
+	mustEnableRegionFailpoint(ctx, cluster, mocktikv.FailpointWriteError, "k2")
+	if client.BatchPut(bss("k1", "k2"), bss("v1", "v2")) == nil {
+		ctx.Log("batch put succeeds while writes to region of k2 fail")
+		return validator.FeatureFail
+	}
+	return validator.FeaturePass
+}
+
+// Batch writes of rawkv are not atomic across regions. When some regions fail,
+// the client should return an error telling which keys failed, keys in other
+// regions may or may not be written.
+var _ = validator.RegisterStory("rawkv batch partial failure", "rawkv.batch-partial-failure")
+
+var _ = validator.RegisterTest("rawkv batch put fails in one region", []string{"rawkv.batch-get", "rawkv.batch-partial-failure"}, testRawKV{}.testBatchPutPartialFailure)
+
+func (t testRawKV) testBatchPutPartialFailure(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := seqKVs(30, 8)
+	mustSplitEvenly(ctx, cluster, keys, 3)
+	mustEnableRegionFailpoint(ctx, cluster, mocktikv.FailpointWriteError, keys[10])
+	err := client.BatchPut(bss(keys...), bss(values...))
+	ctx.AssertNotNil(err, "batch put should fail if a region fails")
+	written := mustDumpPartial(ctx, cluster, keys, values, keys[10:20], false)
+	mustErrMentionKeys(ctx, err, keys[10:20], written)
+
+	// Retrying the batch after the region recovers writes all keys.
+	mustDisableFailpoint(ctx, cluster, mocktikv.FailpointWriteError)
+	t.mustBatchPut(ctx, client, keys, values)
+	t.mustBatchGet(ctx, client, keys, values)
+}
+
+var _ = validator.RegisterTest("rawkv batch delete fails in one region", []string{"rawkv.batch-delete", "rawkv.batch-partial-failure"}, testRawKV{}.testBatchDeletePartialFailure)
+
+func (t testRawKV) testBatchDeletePartialFailure(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := seqKVs(30, 8)
+	mustSplitEvenly(ctx, cluster, keys, 3)
+	mustLoad(ctx, cluster, mocktikv.StorageRaw, zipKVs(keys, values)...)
+	mustEnableRegionFailpoint(ctx, cluster, mocktikv.FailpointWriteError, keys[20])
+	err := client.BatchDelete(bss(keys...))
+	ctx.AssertNotNil(err, "batch delete should fail if a region fails")
+	written := mustDumpPartial(ctx, cluster, keys, values, keys[20:], true)
+	mustErrMentionKeys(ctx, err, keys[20:], written)
+
+	mustDisableFailpoint(ctx, cluster, mocktikv.FailpointWriteError)
+	t.mustBatchDelete(ctx, client, keys)
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "")
+}
+
+var _ = validator.RegisterTest("txnkv commit fails atomically when one region fails", []string{"txnkv.commit", "txnkv.resolve-lock"}, testTxnKV{}.testCommitPartialFailure)
+
+// testCommitPartialFailure checks that unlike rawkv batches, a transaction
+// across regions is either fully committed or not committed at all.
+func (t testTxnKV) testCommitPartialFailure(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := seqKVs(30, 8)
+	mustSplitEvenly(ctx, cluster, keys, 3)
+	mustEnableRegionFailpoint(ctx, cluster, mocktikv.FailpointWriteError, keys[20])
+	txn := t.mustBegin(ctx, client)
+	for i := range keys {
+		t.mustSet(ctx, txn, keys[i], values[i])
+	}
+	ctx.AssertNotNil(txn.Commit(), "commit should fail if a region fails")
+	mustDump(ctx, cluster, mocktikv.StorageTxn, "", "")
+
+	// Locks left by the failed transaction do not block later ones.
+	mustDisableFailpoint(ctx, cluster, mocktikv.FailpointWriteError)
+	mustAdvanceClock(ctx, cluster, time.Minute)
+	t.mustResolvedValues(ctx, cluster, keys, "")
+	t.mustSetKeys(ctx, client, keys, "v-")
+	t.mustResolvedValues(ctx, cluster, keys, "v-")
+}
+
+// mustErrMentionKeys checks that the error tells at least one of the failed
+// keys, and none of the written keys, either as is or hex encoded.
+func mustErrMentionKeys(ctx validator.ExecContext, err error, failed, written []string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	msg := strings.ToLower(err.Error())
+	mentioned := func(k string) bool {
+		return strings.Contains(msg, strings.ToLower(k)) || strings.Contains(msg, hex.EncodeToString([]byte(k)))
+	}
+	for _, k := range written {
+		ctx.Assert(!mentioned(k), fmt.Sprintf("error tells key %q failed, but it is written: %v", k, err))
+	}
+	for _, k := range failed {
+		if mentioned(k) {
+			return
+		}
+	}
+	ctx.Fail(fmt.Sprintf("error should tell which keys failed, got %v", err))
+}
+
+// mustDumpPartial checks rawkv data of the cluster after a batch write partially
+// failed. Stored keys should have the values in the batch, and failed keys
+// should be stored or not as expected. Other keys may or may not be stored,
+// the ones written by the batch are returned.
+func mustDumpPartial(ctx validator.ExecContext, cluster *mocktikv.Cluster, keys, values, failed []string, failedStored bool) (written []string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	kvs, err := cluster.Dump(mocktikv.MockRange{Mode: mocktikv.StorageRaw})
+	ctx.AssertNil(err)
+	expect := make(map[string]string, len(keys))
+	for i := range keys {
+		expect[keys[i]] = values[i]
+	}
+	stored := make(map[string]bool, len(kvs))
+	for _, kv := range kvs {
+		v, ok := expect[string(kv.Key)]
+		ctx.Assert(ok, fmt.Sprintf("unexpected key %q", kv.Key))
+		ctx.AssertEQ(string(kv.Value), v)
+		stored[string(kv.Key)] = true
+	}
+	isFailed := make(map[string]bool, len(failed))
+	for _, k := range failed {
+		ctx.Assert(stored[k] == failedStored, fmt.Sprintf("key %q in the failed region: stored %v, expect %v", k, stored[k], failedStored))
+		isFailed[k] = true
+	}
+	for _, k := range keys {
+		if !isFailed[k] && stored[k] != failedStored {
+			written = append(written, k)
+		}
+	}
+	return written
+}
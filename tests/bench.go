@@ -0,0 +1,125 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"fmt"
+
+	"github.com/tikv/client-validator/mocktikv"
+	"github.com/tikv/client-validator/validator"
+)
+
+// benchKeys is the count of keys loaded before read benchmarks, and the range
+// of keys written by write benchmarks.
+const benchKeys = 1000
+
+var _ = validator.RegisterBenchmark("rawkv.get", []string{"rawkv.get"}, testRawKV{}.benchGet)
+
+func (t testRawKV) benchGet(ctx validator.BenchContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := seqKVs(benchKeys, 64)
+	mustLoad(ctx, cluster, mocktikv.StorageRaw, zipKVs(keys, values)...)
+	ctx.Loop(func() {
+		i := ctx.Rand().Intn(len(keys))
+		t.mustGet(ctx, client, keys[i], values[i])
+	})
+}
+
+var _ = validator.RegisterBenchmark("rawkv.put", []string{"rawkv.put"}, testRawKV{}.benchPut)
+
+func (t testRawKV) benchPut(ctx validator.BenchContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	value := fmt.Sprintf("%064d", 0)
+	ctx.Loop(func() {
+		t.mustPut(ctx, client, fmt.Sprintf("k%05d", ctx.Rand().Intn(benchKeys)), value)
+	})
+}
+
+var _ = validator.RegisterBenchmark("rawkv.batch-get", []string{"rawkv.batch-get"}, testRawKV{}.benchBatchGet)
+
+// benchBatchGet gets 100 keys in a batch, the keys are across 4 regions.
+func (t testRawKV) benchBatchGet(ctx validator.BenchContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := seqKVs(benchKeys, 64)
+	mustSplitEvenly(ctx, cluster, keys, 4)
+	mustLoad(ctx, cluster, mocktikv.StorageRaw, zipKVs(keys, values)...)
+	ctx.Loop(func() {
+		i := ctx.Rand().Intn(len(keys) - 100)
+		t.mustBatchGet(ctx, client, keys[i:i+100], values[i:i+100])
+	})
+}
+
+var _ = validator.RegisterBenchmark("rawkv.scan", []string{"rawkv.scan"}, testRawKV{}.benchScan)
+
+// benchScan scans 100 keys, the keys are across 4 regions.
+func (t testRawKV) benchScan(ctx validator.BenchContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := seqKVs(benchKeys, 64)
+	mustSplitEvenly(ctx, cluster, keys, 4)
+	mustLoad(ctx, cluster, mocktikv.StorageRaw, zipKVs(keys, values)...)
+	ctx.Loop(func() {
+		i := ctx.Rand().Intn(len(keys) - 100)
+		t.mustScan(ctx, client, keys[i], "", 100, zipKVs(keys[i:i+100], values[i:i+100])...)
+	})
+}
+
+var _ = validator.RegisterBenchmark("txnkv.get", []string{"txnkv.begin", "txnkv.get", "txnkv.commit"}, testTxnKV{}.benchGet)
+
+// benchGet runs read-only transactions, each gets a key.
+func (t testTxnKV) benchGet(ctx validator.BenchContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := seqKVs(benchKeys, 64)
+	mustLoad(ctx, cluster, mocktikv.StorageTxn, zipKVs(keys, values)...)
+	ctx.Loop(func() {
+		i := ctx.Rand().Intn(len(keys))
+		txn := t.mustBegin(ctx, client)
+		t.mustGet(ctx, txn, keys[i], values[i])
+		t.mustCommit(ctx, txn)
+	})
+}
+
+var _ = validator.RegisterBenchmark("txnkv.commit", []string{"txnkv.begin", "txnkv.set", "txnkv.commit"}, testTxnKV{}.benchCommit)
+
+// benchCommit runs transactions, each sets 4 keys in different regions.
+func (t testTxnKV) benchCommit(ctx validator.BenchContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, _ := seqKVs(benchKeys, 0)
+	mustSplitEvenly(ctx, cluster, keys, 4)
+	value := fmt.Sprintf("%064d", 0)
+	ctx.Loop(func() {
+		txn := t.mustBegin(ctx, client)
+		for r := 0; r < 4; r++ {
+			t.mustSet(ctx, txn, keys[r*benchKeys/4+ctx.Rand().Intn(benchKeys/4)], value)
+		}
+		t.mustCommit(ctx, txn)
+	})
+}
@@ -0,0 +1,350 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/tikv/client-validator/mocktikv"
+	"github.com/tikv/client-validator/validator"
+)
+
+var (
+	maxKeySize       = flag.Uint64("max-key-size", 8*1024, "max key size (in bytes) of mock clusters in limits tests")
+	raftEntryMaxSize = flag.Uint64("raft-entry-max-size", 1024*1024, "max write request size (in bytes) of mock clusters in limits tests")
+)
+
+// largeBatchSize is the number of keys in large batch tests.
+const largeBatchSize = 10000
+
+var _ = validator.RegisterFeature("rawkv.binary-key", "store keys with arbitrary bytes in raw mod", []string{"rawkv.put"}, testRawKV{}.checkBinaryKey)
+
+func (t testRawKV) checkBinaryKey(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	if err := client.Put([]byte("\x00\xff\x00"), []byte("v")); err != nil {
+		return errToFeatureStatus(err)
+	}
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", "\x00\xff\x00", "v")
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterFeature("rawkv.size-limits", "reject keys and values exceeding cluster limits in raw mod", []string{"rawkv.put"}, testRawKV{}.checkSizeLimits)
+
+func (t testRawKV) checkSizeLimits(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	ctx.AssertNil(cluster.Configure(mocktikv.MockConfig{MaxKeySize: 1024}))
+	err := client.Put(make([]byte, 2048), []byte("v"))
+	return sizeLimitStatus(ctx, err)
+}
+
+var _ = validator.RegisterFeature("txnkv.binary-key", "store keys with arbitrary bytes in a transaction", []string{"txnkv.commit"}, testTxnKV{}.checkBinaryKey)
+
+func (t testTxnKV) checkBinaryKey(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	txn := t.mustBegin(ctx, client)
+	t.mustSet(ctx, txn, "\x00\xff\x00", "v")
+	if err := txn.Commit(); err != nil {
+		return errToFeatureStatus(err)
+	}
+	mustDump(ctx, cluster, mocktikv.StorageTxn, "", "", "\x00\xff\x00", "v")
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterFeature("txnkv.size-limits", "reject keys and values exceeding cluster limits in a transaction", []string{"txnkv.commit"}, testTxnKV{}.checkSizeLimits)
+
+func (t testTxnKV) checkSizeLimits(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	ctx.AssertNil(cluster.Configure(mocktikv.MockConfig{MaxKeySize: 1024}))
+	txn := t.mustBegin(ctx, client)
+	err := txn.Set(make([]byte, 2048), []byte("v"))
+	if err == nil {
+		err = txn.Commit()
+	}
+	return sizeLimitStatus(ctx, err)
+}
+
+// sizeLimitStatus returns the status of a size limits checker by the error of
+// writing a key of 2048 bytes, which exceeds the max key size 1024.
+func sizeLimitStatus(ctx validator.ExecContext, err error) validator.FeatureStatus {
+	if err == nil {
+		ctx.Log("write succeeds with a key of 2048 bytes, max key size is 1024")
+		return validator.FeatureFail
+	}
+	if status := errToFeatureStatus(err); status == validator.FeatureNotImplemented {
+		return status
+	}
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterStory("limits", "rawkv.binary-key", "rawkv.size-limits", "txnkv.binary-key", "txnkv.size-limits")
+
+var _ = validator.RegisterTest("rawkv keys with every byte value", []string{"rawkv.binary-key", "rawkv.batch-put", "rawkv.batch-get", "rawkv.scan", "rawkv.batch-delete"}, testRawKV{}.testBinaryKeys)
+
+func (t testRawKV) testBinaryKeys(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := binaryKVs()
+	mustSplit(ctx, cluster, "\x40", "\x80\x00")
+	t.mustBatchPut(ctx, client, keys, values)
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", zipKVs(keys, values)...)
+	t.mustBatchGet(ctx, client, keys, values)
+	t.mustGet(ctx, client, "\x00", values[0])
+	t.mustGet(ctx, client, "\xff\xff", values[len(values)-1])
+	t.mustScan(ctx, client, "", "", len(keys), zipKVs(keys, values)...)
+	t.mustScan(ctx, client, "\x00\x00", "\x00\xff", 10, keys[1], values[1])
+	t.mustBatchDelete(ctx, client, keys)
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "")
+}
+
+var _ = validator.RegisterTest("txnkv keys with every byte value", []string{"txnkv.binary-key", "txnkv.batch-get", "txnkv.iter", "txnkv.delete"}, testTxnKV{}.testBinaryKeys)
+
+func (t testTxnKV) testBinaryKeys(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := binaryKVs()
+	mustSplit(ctx, cluster, "\x40", "\x80\x00")
+	txn := t.mustBegin(ctx, client)
+	for i := range keys {
+		t.mustSet(ctx, txn, keys[i], values[i])
+	}
+	t.mustCommit(ctx, txn)
+	mustDump(ctx, cluster, mocktikv.StorageTxn, "", "", zipKVs(keys, values)...)
+
+	txn = t.mustBegin(ctx, client)
+	t.mustBatchGet(ctx, txn, keys, values)
+	t.mustGet(ctx, txn, "\x00", values[0])
+	t.mustGet(ctx, txn, "\xff\xff", values[len(values)-1])
+	t.mustIter(ctx, txn, "", "", zipKVs(keys, values)...)
+	t.mustIter(ctx, txn, "\x00\x00", "\x00\xff", keys[1], values[1])
+
+	txn = t.mustBegin(ctx, client)
+	for _, k := range keys {
+		t.mustDelete(ctx, txn, k)
+	}
+	t.mustCommit(ctx, txn)
+	mustDump(ctx, cluster, mocktikv.StorageTxn, "", "")
+}
+
+var _ = validator.RegisterTest("rawkv large keys and values", []string{"rawkv.get", "rawkv.size-limits"}, testRawKV{}.testLargeKV)
+
+func (t testRawKV) testLargeKV(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	mustConfigureLimits(ctx, cluster)
+	keys, values := largeKVs()
+	for i := range keys {
+		t.mustPut(ctx, client, keys[i], values[i])
+		t.mustGet(ctx, client, keys[i], values[i])
+	}
+
+	// Keys or values exceeding the limits are rejected, and the client is
+	// still usable.
+	err := client.Put([]byte(strings.Repeat("k", int(*maxKeySize)+1)), []byte("v"))
+	ctx.AssertNotNil(err, "key exceeding the max key size should be rejected")
+	err = client.Put([]byte("kv"), []byte(strings.Repeat("v", int(*raftEntryMaxSize)+1)))
+	ctx.AssertNotNil(err, "value exceeding the raft entry max size should be rejected")
+	t.mustNotExist(ctx, client, "kv")
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", zipKVs(keys, values)...)
+}
+
+var _ = validator.RegisterTest("txnkv large keys and values", []string{"txnkv.get", "txnkv.size-limits"}, testTxnKV{}.testLargeKV)
+
+func (t testTxnKV) testLargeKV(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	mustConfigureLimits(ctx, cluster)
+	keys, values := largeKVs()
+	for i := range keys {
+		txn := t.mustBegin(ctx, client)
+		t.mustSet(ctx, txn, keys[i], values[i])
+		t.mustCommit(ctx, txn)
+		t.mustGet(ctx, t.mustBegin(ctx, client), keys[i], values[i])
+	}
+
+	// The client may reject the entry when it is set, or fail to commit the
+	// transaction. Either way nothing should be written.
+	mustSetOrCommitFail := func(key, value string, msg string) {
+		ctx.AddCallerDepth(1)
+		defer ctx.AddCallerDepth(-1)
+		txn := t.mustBegin(ctx, client)
+		t.mustSet(ctx, txn, "k", "v")
+		if txn.Set([]byte(key), []byte(value)) == nil {
+			ctx.AssertNotNil(txn.Commit(), msg)
+		} else {
+			t.mustRollback(ctx, txn)
+		}
+	}
+	mustSetOrCommitFail(strings.Repeat("k", int(*maxKeySize)+1), "v", "key exceeding the max key size should be rejected")
+	mustSetOrCommitFail("kv", strings.Repeat("v", int(*raftEntryMaxSize)+1), "value exceeding the raft entry max size should be rejected")
+	mustDump(ctx, cluster, mocktikv.StorageTxn, "", "", zipKVs(keys, values)...)
+}
+
+var _ = validator.RegisterTest("rawkv batch of 10k keys across regions", []string{"rawkv.batch-put", "rawkv.batch-get", "rawkv.scan", "rawkv.batch-delete"}, testRawKV{}.testLargeBatch)
+
+func (t testRawKV) testLargeBatch(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := seqKVs(largeBatchSize, 16)
+	mustSplitEvenly(ctx, cluster, keys, 10)
+	t.mustBatchPut(ctx, client, keys, values)
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", zipKVs(keys, values)...)
+	t.mustBatchGet(ctx, client, keys, values)
+	t.mustScan(ctx, client, "", "", len(keys), zipKVs(keys, values)...)
+	t.mustBatchDelete(ctx, client, keys)
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "")
+}
+
+var _ = validator.RegisterTest("txnkv transaction of 10k keys across regions", []string{"txnkv.batch-get", "txnkv.iter", "txnkv.commit"}, testTxnKV{}.testLargeBatch)
+
+func (t testTxnKV) testLargeBatch(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := seqKVs(largeBatchSize, 16)
+	mustSplitEvenly(ctx, cluster, keys, 10)
+	txn := t.mustBegin(ctx, client)
+	for i := range keys {
+		t.mustSet(ctx, txn, keys[i], values[i])
+	}
+	t.mustCommit(ctx, txn)
+	mustDump(ctx, cluster, mocktikv.StorageTxn, "", "", zipKVs(keys, values)...)
+
+	txn = t.mustBegin(ctx, client)
+	t.mustBatchGet(ctx, txn, keys, values)
+	t.mustIter(ctx, txn, "", "", zipKVs(keys, values)...)
+}
+
+var _ = validator.RegisterTest("rawkv batch exceeding raft entry max size is split", []string{"rawkv.batch-put", "rawkv.batch-get", "rawkv.batch-delete", "rawkv.size-limits"}, testRawKV{}.testOversizedBatch)
+
+// testOversizedBatch writes a batch whose total size is several times of the
+// raft entry max size, the client should split it into smaller requests.
+func (t testRawKV) testOversizedBatch(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	mustConfigureLimits(ctx, cluster)
+	keys, values := seqKVs(64, int(*raftEntryMaxSize)/16)
+	mustSplitEvenly(ctx, cluster, keys, 2)
+	t.mustBatchPut(ctx, client, keys, values)
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", zipKVs(keys, values)...)
+	t.mustBatchGet(ctx, client, keys, values)
+	t.mustBatchDelete(ctx, client, keys)
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "")
+}
+
+var _ = validator.RegisterTest("txnkv transaction exceeding raft entry max size is committed in batches", []string{"txnkv.batch-get", "txnkv.size-limits"}, testTxnKV{}.testOversizedBatch)
+
+func (t testTxnKV) testOversizedBatch(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	mustConfigureLimits(ctx, cluster)
+	keys, values := seqKVs(64, int(*raftEntryMaxSize)/16)
+	mustSplitEvenly(ctx, cluster, keys, 2)
+	txn := t.mustBegin(ctx, client)
+	for i := range keys {
+		t.mustSet(ctx, txn, keys[i], values[i])
+	}
+	t.mustCommit(ctx, txn)
+	mustDump(ctx, cluster, mocktikv.StorageTxn, "", "", zipKVs(keys, values)...)
+	t.mustBatchGet(ctx, t.mustBegin(ctx, client), keys, values)
+}
+
+// mustConfigureLimits configures the cluster with limits given by flags.
+func mustConfigureLimits(ctx validator.ExecContext, cluster *mocktikv.Cluster) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	err := cluster.Configure(mocktikv.MockConfig{
+		MaxKeySize:       *maxKeySize,
+		RaftEntryMaxSize: *raftEntryMaxSize,
+	})
+	ctx.AssertNil(err)
+}
+
+// mustSplitEvenly splits the cluster into n regions, each contains about the
+// same number of keys. Keys should be sorted.
+func mustSplitEvenly(ctx validator.ExecContext, cluster *mocktikv.Cluster, keys []string, n int) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	var splitKeys [][]byte
+	for i := 1; i < n; i++ {
+		splitKeys = append(splitKeys, []byte(keys[i*len(keys)/n]))
+	}
+	err := cluster.Split(splitKeys...)
+	ctx.AssertNil(err)
+}
+
+// binaryKVs returns sorted keys which contain every byte value, including
+// keys of a single byte and keys ending with 0x00 or 0xff.
+func binaryKVs() (keys, values []string) {
+	for b := 0; b < 256; b++ {
+		for _, k := range []string{string([]byte{byte(b)}), string([]byte{byte(b), 0x00}), string([]byte{byte(b), 0xff})} {
+			keys = append(keys, k)
+			values = append(values, fmt.Sprintf("v%x", k))
+		}
+	}
+	return
+}
+
+// largeKVs returns key-value pairs of sizes close to the limits given by flags.
+func largeKVs() (keys, values []string) {
+	keys = append(keys, strings.Repeat("a", 4096), strings.Repeat("b", int(*maxKeySize)), "c")
+	values = append(values, "v", "v", strings.Repeat("v", int(*raftEntryMaxSize)/2))
+	return
+}
+
+// seqKVs returns n sorted keys and values of the given size.
+func seqKVs(n int, valueSize int) (keys, values []string) {
+	for i := 0; i < n; i++ {
+		keys = append(keys, fmt.Sprintf("k%05d", i))
+		values = append(values, fmt.Sprintf("%0*d", valueSize, i))
+	}
+	return
+}
+
+// zipKVs returns key-value pairs as key, value, key, value...
+func zipKVs(keys, values []string) []string {
+	kvs := make([]string, 0, len(keys)*2)
+	for i := range keys {
+		kvs = append(kvs, keys[i], values[i])
+	}
+	return kvs
+}
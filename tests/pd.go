@@ -0,0 +1,290 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tikv/client-validator/mocktikv"
+	"github.com/tikv/client-validator/stub"
+	"github.com/tikv/client-validator/validator"
+)
+
+var pdErrorTimeout = flag.Duration("pd-error-timeout", 10*time.Second, "max time for clients to return errors when PD is unreachable")
+
+// tsoCallers and tsoCalls are the count of goroutines calling GetTS
+// concurrently, and the count of calls by each goroutine.
+const tsoCallers, tsoCalls = 16, 50
+
+var _ = validator.RegisterFeature("pd.failover", "get timestamps across PD leader changes", []string{"txnkv.get-ts"}, testTxnKV{}.checkPDFailover)
+
+func (t testTxnKV) checkPDFailover(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	ts := t.mustGetTS(ctx, client)
+	err := cluster.FailoverPD()
+	ctx.AssertNil(err)
+	next, err := client.GetTS()
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	if next <= ts {
+		ctx.Log("ts after PD failover is not increasing: %d <= %d", next, ts)
+		return validator.FeatureFail
+	}
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterFeature("pd.unavailable", "return errors in time when PD is unreachable", []string{"txnkv.get-ts"}, testTxnKV{}.checkPDUnavailable)
+
+func (t testTxnKV) checkPDUnavailable(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	t.mustGetTS(ctx, client)
+	mustEnableFailpoint(ctx, cluster, mocktikv.FailpointPDUnavailable)
+	defer mustDisableFailpoint(ctx, cluster, mocktikv.FailpointPDUnavailable)
+	returned, err := callWithin(*pdErrorTimeout, func() error {
+		_, err := client.GetTS()
+		return err
+	})
+	if !returned || isCallTimeoutErr(err) {
+		ctx.Log("GetTS does not return while PD is unreachable, timed out after %v", *pdErrorTimeout)
+		return validator.FeatureFail
+	}
+	if err == nil {
+		ctx.Log("GetTS returns nil error while PD is unreachable")
+		return validator.FeatureFail
+	}
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterStory("pd", "pd.failover", "pd.unavailable")
+
+var _ = validator.RegisterTest("timestamps are strictly monotonic across concurrent callers", []string{"txnkv.get-ts"}, testTxnKV{}.testConcurrentTS)
+
+func (t testTxnKV) testConcurrentTS(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	first := t.mustGetTS(ctx, client)
+	tss, err := getTSConcurrently(client, tsoCallers, tsoCalls)
+	ctx.AssertNil(err)
+	last := t.mustGetTS(ctx, client)
+	mustMonotonicTS(ctx, first, last, tss)
+}
+
+var _ = validator.RegisterTest("start timestamps of transactions are strictly monotonic", []string{"txnkv.begin", "txnkv.set", "txnkv.commit", "txnkv.get-ts"}, testTxnKV{}.testConcurrentBeginTS)
+
+// testConcurrentBeginTS commits transactions concurrently, each caller writes
+// its own key, and checks start timestamps of versions written.
+func (t testTxnKV) testConcurrentBeginTS(ctx validator.ExecContext) {
+	const callers, txns = 8, 10
+
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	first := t.mustGetTS(ctx, client)
+	var wg sync.WaitGroup
+	errCh := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			for j := 0; j < txns; j++ {
+				if err := t.setKey(client, key, fmt.Sprintf("v%d", j)); err != nil {
+					errCh <- err
+					return
+				}
+			}
+		}(fmt.Sprintf("k%d", i))
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		ctx.AssertNil(err)
+	}
+	last := t.mustGetTS(ctx, client)
+
+	kvs, err := cluster.Dump(mocktikv.MockRange{Mode: mocktikv.StorageTxn})
+	ctx.AssertNil(err)
+	ctx.AssertEQ(len(kvs), callers*txns)
+	// Versions of a key are ordered by commit timestamp descending, so start
+	// timestamps of each caller are reversed.
+	tss := make(map[string][]uint64)
+	for _, kv := range kvs {
+		ctx.Assert(kv.CommitTS > kv.StartTS, fmt.Sprintf("expect commit ts > start ts, got %d <= %d", kv.CommitTS, kv.StartTS))
+		tss[string(kv.Key)] = append([]uint64{kv.StartTS}, tss[string(kv.Key)]...)
+	}
+	var callerTSs [][]uint64
+	for _, ts := range tss {
+		callerTSs = append(callerTSs, ts)
+	}
+	mustMonotonicTS(ctx, first, last, callerTSs)
+}
+
+var _ = validator.RegisterTest("timestamps are strictly monotonic across PD leader changes", []string{"txnkv.begin", "txnkv.set", "txnkv.commit", "pd.failover"}, testTxnKV{}.testFailoverTS)
+
+func (t testTxnKV) testFailoverTS(ctx validator.ExecContext) {
+	const rounds = 3
+
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	var before, after []uint64
+	for i := 0; i < rounds; i++ {
+		before = append(before, t.mustGetTS(ctx, client))
+		err := cluster.FailoverPD()
+		ctx.AssertNil(err)
+		err = t.setKey(client, fmt.Sprintf("k%d", i), "v")
+		ctx.AssertNil(err)
+		after = append(after, t.mustGetTS(ctx, client))
+	}
+
+	kvs, err := cluster.Dump(mocktikv.MockRange{Mode: mocktikv.StorageTxn})
+	ctx.AssertNil(err)
+	ctx.AssertEQ(len(kvs), rounds)
+	for i, kv := range kvs {
+		ctx.Assert(before[i] < kv.StartTS && kv.StartTS < kv.CommitTS && kv.CommitTS < after[i],
+			fmt.Sprintf("round %d: expect %d < start ts %d < commit ts %d < %d", i, before[i], kv.StartTS, kv.CommitTS, after[i]))
+	}
+}
+
+var _ = validator.RegisterTest("client recovers after PD is reachable again", []string{"txnkv.begin", "pd.unavailable"}, testTxnKV{}.testPDRecover)
+
+func (t testTxnKV) testPDRecover(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	ts := t.mustGetTS(ctx, client)
+	mustEnableFailpoint(ctx, cluster, mocktikv.FailpointPDUnavailable)
+	returned, err := callWithin(*pdErrorTimeout, func() error {
+		_, err := client.Begin()
+		return err
+	})
+	ctx.Assert(returned && !isCallTimeoutErr(err), fmt.Sprintf("expect Begin to return in %v while PD is unreachable", *pdErrorTimeout))
+	ctx.AssertNotNil(err)
+
+	mustDisableFailpoint(ctx, cluster, mocktikv.FailpointPDUnavailable)
+	next := t.mustGetTS(ctx, client)
+	ctx.Assert(next > ts, fmt.Sprintf("expect ts > %d, got %d", ts, next))
+	txn := t.mustBegin(ctx, client)
+	t.mustRollback(ctx, txn)
+}
+
+var _ = validator.RegisterTest("TSO requests are batched under load", []string{"txnkv.get-ts"}, testTxnKV{}.testTSOBatching)
+
+// testTSOBatching checks that timestamps requested concurrently are fetched
+// from PD in batches, at least 2 timestamps per request on average.
+func (t testTxnKV) testTSOBatching(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	t.mustGetTS(ctx, client)
+	mustResetStats(ctx, cluster)
+	_, err := getTSConcurrently(client, tsoCallers, tsoCalls)
+	ctx.AssertNil(err)
+	stats := mustStats(ctx, cluster)
+	mustPDCommandsAtMost(ctx, stats, "Tso", tsoCallers*tsoCalls/2)
+}
+
+// setKey sets the key in a new transaction.
+func (t testTxnKV) setKey(client stub.TxnKV, key, value string) error {
+	txn, err := client.Begin()
+	if err != nil {
+		return err
+	}
+	if err = txn.Set([]byte(key), []byte(value)); err != nil {
+		txn.Rollback()
+		return err
+	}
+	return txn.Commit()
+}
+
+// getTSConcurrently calls GetTS n times in each of callers goroutines, and
+// returns timestamps got by each caller in order.
+func getTSConcurrently(client stub.TxnKV, callers, n int) ([][]uint64, error) {
+	tss := make([][]uint64, callers)
+	var wg sync.WaitGroup
+	errCh := make(chan error, callers)
+	for i := range tss {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				ts, err := client.GetTS()
+				if err != nil {
+					errCh <- err
+					return
+				}
+				tss[i] = append(tss[i], ts)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errCh)
+	return tss, <-errCh
+}
+
+// mustMonotonicTS checks timestamps got by concurrent callers. Timestamps of
+// each caller should be increasing, all timestamps should be distinct, and
+// they should be between first and last, which are got before and after.
+func mustMonotonicTS(ctx validator.ExecContext, first, last uint64, tss [][]uint64) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	seen := make(map[uint64]bool)
+	for _, ts := range tss {
+		prev := first
+		for _, t := range ts {
+			ctx.Assert(t > prev, fmt.Sprintf("expect ts > %d, got %d", prev, t))
+			ctx.Assert(!seen[t], fmt.Sprintf("ts %d is got more than once", t))
+			seen[t] = true
+			prev = t
+		}
+		ctx.Assert(last > prev, fmt.Sprintf("expect ts > %d, got %d", prev, last))
+	}
+}
+
+// callWithin calls f and waits for it to return at most d. If f does not
+// return in time, it is left running in the background.
+func callWithin(d time.Duration, f func() error) (returned bool, err error) {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- f()
+	}()
+	select {
+	case err = <-errCh:
+		return true, err
+	case <-time.After(d):
+		return false, nil
+	}
+}
+
+// isCallTimeoutErr checks whether err is a timeout of the harness rather than
+// an error returned by the client.
+func isCallTimeoutErr(err error) bool {
+	return errors.Cause(err) == stub.ErrCallTimeout
+}
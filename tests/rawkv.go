@@ -15,7 +15,11 @@ package tests
 
 import (
 	"fmt"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/tikv/client-validator/mocktikv"
 	"github.com/tikv/client-validator/stub"
 	"github.com/tikv/client-validator/validator"
@@ -34,7 +38,7 @@ var _ = validator.RegisterFeature("rawkv.new", "create a rawkv client", nil, tes
 func (t testRawKV) checkClientCreate(ctx validator.ExecContext) validator.FeatureStatus {
 	cluster := t.newCluster(ctx)
 	defer cluster.Close()
-	client, err := stub.NewRawClientStub(*clientProxyAddr, cluster.PDAddrs())
+	client, err := newRawClient(cluster.PDAddrs())
 	if err != nil {
 		return errToFeatureStatus(err)
 	}
@@ -42,9 +46,9 @@ func (t testRawKV) checkClientCreate(ctx validator.ExecContext) validator.Featur
 	return validator.FeaturePass
 }
 
-func (t testRawKV) newClient(ctx validator.ExecContext) (*mocktikv.Cluster, *stub.RawClientStub) {
+func (t testRawKV) newClient(ctx validator.ExecContext) (*mocktikv.Cluster, stub.RawKV) {
 	cluster := t.newCluster(ctx)
-	client, err := stub.NewRawClientStub(*clientProxyAddr, cluster.PDAddrs())
+	client, err := newRawClient(cluster.PDAddrs())
 	ctx.AssertNil(err)
 	return cluster, client
 }
@@ -66,7 +70,11 @@ func (t testRawKV) checkPut(ctx validator.ExecContext) validator.FeatureStatus {
 	defer client.Close()
 
 	err := client.Put([]byte("k"), []byte("v"))
-	return errToFeatureStatus(err)
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", "k", "v")
+	return validator.FeaturePass
 }
 
 var _ = validator.RegisterFeature("rawkv.get", "load key-value pair in raw mod", []string{"rawkv.new"}, testRawKV{}.checkGet)
@@ -76,40 +84,42 @@ func (t testRawKV) checkGet(ctx validator.ExecContext) validator.FeatureStatus {
 	defer cluster.Close()
 	defer client.Close()
 
-	val, err := client.Get([]byte("k"))
+	mustLoad(ctx, cluster, mocktikv.StorageRaw, "k1", "v1")
+	val, err := client.Get([]byte("k1"))
 	if err != nil {
 		return errToFeatureStatus(err)
 	}
+	ctx.AssertEQ(string(val), "v1")
+	val, err = client.Get([]byte("k2"))
+	ctx.AssertNil(err)
 	ctx.Assert(len(val) == 0, "expect empty value")
 	return validator.FeaturePass
 }
 
-var _ = validator.RegisterFeature("rawkv.delete", "delete key-value pair in raw mod", []string{"rawkv.put"}, testRawKV{}.checkDelete)
+var _ = validator.RegisterFeature("rawkv.delete", "delete key-value pair in raw mod", []string{"rawkv.new"}, testRawKV{}.checkDelete)
 
 func (t testRawKV) checkDelete(ctx validator.ExecContext) validator.FeatureStatus {
 	cluster, client := t.newClient(ctx)
 	defer cluster.Close()
 	defer client.Close()
 
-	err := client.Put([]byte("k"), []byte("v"))
-	ctx.AssertNil(err)
-	err = client.Delete([]byte("k"))
-	return errToFeatureStatus(err)
+	mustLoad(ctx, cluster, mocktikv.StorageRaw, "k1", "v1", "k2", "v2")
+	err := client.Delete([]byte("k1"))
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", "k2", "v2")
+	return validator.FeaturePass
 }
 
-// Ideally, `scan` does not have to depend on `put`. However, mock-tikv does not
-// support inject data directly now, so we need `put` to prepare some data.
-var _ = validator.RegisterFeature("rawkv.scan", "scan key-value pairs in raw mod", []string{"rawkv.put"}, testRawKV{}.checkScan)
+var _ = validator.RegisterFeature("rawkv.scan", "scan key-value pairs in raw mod", []string{"rawkv.new"}, testRawKV{}.checkScan)
 
 func (t testRawKV) checkScan(ctx validator.ExecContext) validator.FeatureStatus {
 	cluster, client := t.newClient(ctx)
 	defer cluster.Close()
 	defer client.Close()
 
-	err := client.Put([]byte("k1"), []byte("v1"))
-	ctx.AssertNil(err)
-	err = client.Put([]byte("k2"), []byte("v2"))
-	ctx.AssertNil(err)
+	mustLoad(ctx, cluster, mocktikv.StorageRaw, "k1", "v1", "k2", "v2")
 	keys, values, err := client.Scan(nil, nil, 2)
 	if err != nil {
 		return errToFeatureStatus(err)
@@ -128,12 +138,13 @@ func (t testRawKV) checkBatchGet(ctx validator.ExecContext) validator.FeatureSta
 	defer cluster.Close()
 	defer client.Close()
 
+	mustLoad(ctx, cluster, mocktikv.StorageRaw, "k1", "v1")
 	values, err := client.BatchGet(bss("k1", "k2"))
 	if err != nil {
 		return errToFeatureStatus(err)
 	}
 	ctx.AssertEQ(len(values), 2, "batch get should return equal lenght with keys (even if not exist)")
-	ctx.AssertEQ(len(values[0]), 0)
+	ctx.AssertEQ(string(values[0]), "v1")
 	ctx.AssertEQ(len(values[1]), 0)
 	return validator.FeaturePass
 }
@@ -146,7 +157,11 @@ func (t testRawKV) checkBatchPut(ctx validator.ExecContext) validator.FeatureSta
 	defer client.Close()
 
 	err := client.BatchPut(bss("k1", "k2"), bss("v1", "v2"))
-	return errToFeatureStatus(err)
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", "k1", "v1", "k2", "v2")
+	return validator.FeaturePass
 }
 
 var _ = validator.RegisterFeature("rawkv.batch-delete", "delete rawkv in batches", []string{"rawkv.new"}, testRawKV{}.checkBatchDelete)
@@ -156,8 +171,13 @@ func (t testRawKV) checkBatchDelete(ctx validator.ExecContext) validator.Feature
 	defer cluster.Close()
 	defer client.Close()
 
+	mustLoad(ctx, cluster, mocktikv.StorageRaw, "k1", "v1", "k2", "v2", "k3", "v3")
 	err := client.BatchDelete(bss("k1", "k2"))
-	return errToFeatureStatus(err)
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", "k3", "v3")
+	return validator.FeaturePass
 }
 
 var _ = validator.RegisterStory("rawkv batch operations", "rawkv.batch-get", "rawkv.batch-put", "rawkv.batch-delete")
@@ -169,10 +189,153 @@ func (t testRawKV) checkDeleteRange(ctx validator.ExecContext) validator.Feature
 	defer cluster.Close()
 	defer client.Close()
 
+	mustLoad(ctx, cluster, mocktikv.StorageRaw, "j", "v0", "k1", "v1", "k2", "v2")
 	err := client.DeleteRange([]byte("k"), nil)
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", "j", "v0")
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterFeature("rawkv.reverse-scan", "scan key-value pairs in reverse order in raw mod", []string{"rawkv.new"}, testRawKV{}.checkReverseScan)
+
+func (t testRawKV) checkReverseScan(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	mustLoad(ctx, cluster, mocktikv.StorageRaw, "k1", "v1", "k2", "v2")
+	keys, values, err := client.ReverseScan([]byte("k3"), nil, 2)
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	ctx.AssertDeepEQ(keys, bss("k2", "k1"))
+	ctx.AssertDeepEQ(values, bss("v2", "v1"))
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterFeature("rawkv.scan-key-only", "scan keys without values in raw mod", []string{"rawkv.new"}, testRawKV{}.checkScanKeyOnly)
+
+func (t testRawKV) checkScanKeyOnly(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	mustLoad(ctx, cluster, mocktikv.StorageRaw, "k1", "v1", "k2", "v2")
+	keys, values, err := client.ScanKeyOnly(nil, nil, 2)
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	ctx.AssertDeepEQ(keys, bss("k1", "k2"))
+	for i, v := range values {
+		if len(v) > 0 {
+			ctx.Log("key-only scan returns value %q at %d", v, i)
+			return validator.FeatureFail
+		}
+	}
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterStory("rawkv scan options", "rawkv.reverse-scan", "rawkv.scan-key-only")
+
+var _ = validator.RegisterFeature("rawkv.put-ttl", "store key-value pair with TTL in raw mod", []string{"rawkv.new"}, testRawKV{}.checkPutWithTTL)
+
+func (t testRawKV) checkPutWithTTL(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	err := client.PutWithTTL([]byte("k"), []byte("v"), 100)
+	return errToFeatureStatus(err)
+}
+
+var _ = validator.RegisterFeature("rawkv.batch-put-ttl", "put rawkv with TTL in batches", []string{"rawkv.new"}, testRawKV{}.checkBatchPutWithTTL)
+
+func (t testRawKV) checkBatchPutWithTTL(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	err := client.BatchPutWithTTL(bss("k1", "k2"), bss("v1", "v2"), []uint64{100, 200})
+	return errToFeatureStatus(err)
+}
+
+var _ = validator.RegisterFeature("rawkv.get-key-ttl", "query remaining TTL of a key in raw mod", []string{"rawkv.put-ttl"}, testRawKV{}.checkGetKeyTTL)
+
+func (t testRawKV) checkGetKeyTTL(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	err := client.PutWithTTL([]byte("k"), []byte("v"), 100)
+	ctx.AssertNil(err)
+	ttl, err := client.GetKeyTTL([]byte("k"))
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	ctx.Assert(ttl != nil, "expect ttl of existing key")
+	ctx.Assert(*ttl > 0 && *ttl <= 100, fmt.Sprintf("expect ttl in (0, 100], got %v", *ttl))
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterStory("rawkv TTL", "rawkv.put-ttl", "rawkv.batch-put-ttl", "rawkv.get-key-ttl")
+
+var _ = validator.RegisterFeature("rawkv.atomic-for-cas", "toggle atomic mode for compare-and-swap", []string{"rawkv.new"}, testRawKV{}.checkAtomicForCAS)
+
+func (t testRawKV) checkAtomicForCAS(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	if err := client.SetAtomicForCAS(true); err != nil {
+		return errToFeatureStatus(err)
+	}
+	err := client.SetAtomicForCAS(false)
 	return errToFeatureStatus(err)
 }
 
+var _ = validator.RegisterFeature("rawkv.cas", "compare and swap in raw mod", []string{"rawkv.atomic-for-cas"}, testRawKV{}.checkCAS)
+
+func (t testRawKV) checkCAS(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	err := client.SetAtomicForCAS(true)
+	ctx.AssertNil(err)
+	prev, ok, err := client.CompareAndSwap([]byte("k"), nil, []byte("v"), true)
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	ctx.Assert(ok, "expect swap succeed")
+	ctx.AssertEQ(len(prev), 0)
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterStory("rawkv compare-and-swap", "rawkv.atomic-for-cas", "rawkv.cas")
+
+var _ = validator.RegisterFeature("rawkv.column-family", "use column families in raw mod", []string{"rawkv.get", "rawkv.put"}, testRawKV{}.checkColumnFamily)
+
+func (t testRawKV) checkColumnFamily(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	lock := client.WithCF("lock")
+	err := lock.Put([]byte("k"), []byte("v"))
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	val, err := lock.Get([]byte("k"))
+	ctx.AssertNil(err)
+	ctx.AssertEQ(string(val), "v")
+	val, err = client.WithCF("default").Get([]byte("k"))
+	ctx.AssertNil(err)
+	ctx.Assert(len(val) == 0, "expect empty value in another column family")
+	return validator.FeaturePass
+}
+
 var _ = validator.RegisterTest("simple rawkv get/put/delete", []string{"rawkv.get", "rawkv.put", "rawkv.delete"}, testRawKV{}.testSimple)
 
 func (t testRawKV) testSimple(ctx validator.ExecContext) {
@@ -216,7 +379,7 @@ func (t testRawKV) testBatch(ctx validator.ExecContext) {
 		values = append(values, value)
 		t.mustNotExist(ctx, client, key)
 	}
-	t.mustSplit(ctx, cluster, "", fmt.Sprint("key", n/2))
+	mustSplit(ctx, cluster, "", fmt.Sprint("key", n/2))
 	t.mustBatchPut(ctx, client, keys, values)
 	t.mustBatchGet(ctx, client, keys, values)
 	t.mustBatchDelete(ctx, client, keys)
@@ -243,7 +406,7 @@ func (t testRawKV) testRegionSplit(ctx validator.ExecContext) {
 
 	t.mustPut(ctx, client, "k1", "v1")
 	t.mustPut(ctx, client, "k3", "v3")
-	t.mustSplit(ctx, cluster, "k", "k2")
+	mustSplit(ctx, cluster, "k", "k2")
 	t.mustGet(ctx, client, "k1", "v1")
 	t.mustGet(ctx, client, "k3", "v3")
 }
@@ -270,12 +433,339 @@ func (t testRawKV) testScan(ctx validator.ExecContext) {
 	}
 
 	check()
-	t.mustSplit(ctx, cluster, "k", "k2")
+	mustSplit(ctx, cluster, "k", "k2")
+	check()
+	mustSplit(ctx, cluster, "k2", "k5")
+	check()
+}
+
+var _ = validator.RegisterTest("scan with zero limit and limit larger than data", []string{"rawkv.batch-put", "rawkv.scan"}, testRawKV{}.testScanLimit)
+
+func (t testRawKV) testScanLimit(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	t.mustBatchPut(ctx, client, []string{"k1", "k3", "k5", "k7"}, []string{"v1", "v3", "v5", "v7"})
+	check := func() {
+		t.mustScan(ctx, client, "", "", 0)
+		t.mustScan(ctx, client, "k3", "k5", 0)
+		t.mustScan(ctx, client, "", "", 4, "k1", "v1", "k3", "v3", "k5", "v5", "k7", "v7")
+		t.mustScan(ctx, client, "", "", 5, "k1", "v1", "k3", "v3", "k5", "v5", "k7", "v7")
+		t.mustScan(ctx, client, "", "", 10240, "k1", "v1", "k3", "v3", "k5", "v5", "k7", "v7")
+		t.mustScan(ctx, client, "k4", "", 100, "k5", "v5", "k7", "v7")
+		t.mustScan(ctx, client, "k8", "", 100)
+	}
+
 	check()
-	t.mustSplit(ctx, cluster, "k2", "k5")
+	mustSplit(ctx, cluster, "k", "k4")
 	check()
 }
 
+var _ = validator.RegisterTest("reverse scan", []string{"rawkv.batch-put", "rawkv.reverse-scan"}, testRawKV{}.testReverseScan)
+
+func (t testRawKV) testReverseScan(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	t.mustBatchPut(ctx, client, []string{"k1", "k3", "k5", "k7"}, []string{"v1", "v3", "v5", "v7"})
+	check := func() {
+		t.mustReverseScan(ctx, client, "z", "", 0)
+		t.mustReverseScan(ctx, client, "z", "", 1, "k7", "v7")
+		t.mustReverseScan(ctx, client, "k7", "", 2, "k5", "v5", "k3", "v3")
+		t.mustReverseScan(ctx, client, "k7\x00", "", 2, "k7", "v7", "k5", "v5")
+		t.mustReverseScan(ctx, client, "z", "", 10, "k7", "v7", "k5", "v5", "k3", "v3", "k1", "v1")
+		t.mustReverseScan(ctx, client, "k6", "k3", 10, "k5", "v5", "k3", "v3")
+		t.mustReverseScan(ctx, client, "k6", "k3\x00", 10, "k5", "v5")
+		t.mustReverseScan(ctx, client, "k1", "", 10)
+		t.mustReverseScan(ctx, client, "k5", "k5", 10)
+	}
+
+	check()
+	mustSplit(ctx, cluster, "k", "k2")
+	check()
+	mustSplit(ctx, cluster, "k2", "k5")
+	check()
+}
+
+var _ = validator.RegisterTest("key-only scan", []string{"rawkv.batch-put", "rawkv.scan-key-only"}, testRawKV{}.testScanKeyOnly)
+
+func (t testRawKV) testScanKeyOnly(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	t.mustBatchPut(ctx, client, []string{"k1", "k3", "k5", "k7"}, []string{"v1", "v3", "v5", "v7"})
+	check := func() {
+		t.mustScanKeyOnly(ctx, client, "", "", 0)
+		t.mustScanKeyOnly(ctx, client, "", "", 1, "k1")
+		t.mustScanKeyOnly(ctx, client, "k2", "", 2, "k3", "k5")
+		t.mustScanKeyOnly(ctx, client, "k1", "k5", 10, "k1", "k3")
+		t.mustScanKeyOnly(ctx, client, "", "", 10, "k1", "k3", "k5", "k7")
+	}
+
+	check()
+	mustSplit(ctx, cluster, "k", "k4")
+	check()
+}
+
+var _ = validator.RegisterTest("key expires after TTL", []string{"rawkv.get", "rawkv.put-ttl", "rawkv.get-key-ttl"}, testRawKV{}.testTTL)
+
+func (t testRawKV) testTTL(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	t.mustPutWithTTL(ctx, client, "k1", "v1", 10)
+	t.mustPutWithTTL(ctx, client, "k2", "v2", 20)
+	t.mustGet(ctx, client, "k1", "v1")
+	t.mustKeyTTL(ctx, client, "k1", 1, 10)
+	t.mustKeyTTL(ctx, client, "k2", 1, 20)
+
+	mustAdvanceClock(ctx, cluster, 5*time.Second)
+	t.mustGet(ctx, client, "k1", "v1")
+	t.mustKeyTTL(ctx, client, "k1", 1, 5)
+
+	mustAdvanceClock(ctx, cluster, 6*time.Second)
+	t.mustNotExist(ctx, client, "k1")
+	t.mustNoKeyTTL(ctx, client, "k1")
+	t.mustGet(ctx, client, "k2", "v2")
+	t.mustKeyTTL(ctx, client, "k2", 1, 9)
+
+	// Put again resets the TTL.
+	t.mustPutWithTTL(ctx, client, "k2", "v2", 20)
+	mustAdvanceClock(ctx, cluster, 15*time.Second)
+	t.mustGet(ctx, client, "k2", "v2")
+	mustAdvanceClock(ctx, cluster, 10*time.Second)
+	t.mustNotExist(ctx, client, "k2")
+}
+
+var _ = validator.RegisterTest("key without TTL never expires", []string{"rawkv.get", "rawkv.put", "rawkv.get-key-ttl"}, testRawKV{}.testNoTTL)
+
+func (t testRawKV) testNoTTL(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	t.mustNoKeyTTL(ctx, client, "k1")
+	t.mustPut(ctx, client, "k1", "v1")
+	t.mustPutWithTTL(ctx, client, "k2", "v2", 0)
+	t.mustKeyTTL(ctx, client, "k1", 0, 0)
+	t.mustKeyTTL(ctx, client, "k2", 0, 0)
+	mustAdvanceClock(ctx, cluster, 24*time.Hour)
+	t.mustGet(ctx, client, "k1", "v1")
+	t.mustGet(ctx, client, "k2", "v2")
+
+	// Put without TTL clears the TTL.
+	t.mustPutWithTTL(ctx, client, "k3", "v3", 10)
+	t.mustPut(ctx, client, "k3", "v3")
+	t.mustKeyTTL(ctx, client, "k3", 0, 0)
+	mustAdvanceClock(ctx, cluster, time.Minute)
+	t.mustGet(ctx, client, "k3", "v3")
+}
+
+var _ = validator.RegisterTest("batch put with TTL", []string{"rawkv.batch-get", "rawkv.batch-put-ttl"}, testRawKV{}.testBatchPutWithTTL)
+
+func (t testRawKV) testBatchPutWithTTL(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys := []string{"k1", "k2", "k3", "k4"}
+	mustSplit(ctx, cluster, "", "k3")
+	err := client.BatchPutWithTTL(bss(keys...), bss("v1", "v2", "v3", "v4"), []uint64{10, 20, 10, 0})
+	ctx.AssertNil(err)
+	t.mustBatchGet(ctx, client, keys, []string{"v1", "v2", "v3", "v4"})
+	mustAdvanceClock(ctx, cluster, 15*time.Second)
+	t.mustBatchGet(ctx, client, keys, []string{"", "v2", "", "v4"})
+	mustAdvanceClock(ctx, cluster, 10*time.Second)
+	t.mustBatchGet(ctx, client, keys, []string{"", "", "", "v4"})
+}
+
+var _ = validator.RegisterTest("expired keys are invisible to scan", []string{"rawkv.put-ttl", "rawkv.scan"}, testRawKV{}.testScanTTL)
+
+func (t testRawKV) testScanTTL(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	t.mustPutWithTTL(ctx, client, "k1", "v1", 10)
+	t.mustPutWithTTL(ctx, client, "k2", "v2", 0)
+	t.mustPutWithTTL(ctx, client, "k3", "v3", 10)
+	t.mustScan(ctx, client, "", "", 10, "k1", "v1", "k2", "v2", "k3", "v3")
+	mustAdvanceClock(ctx, cluster, 11*time.Second)
+	t.mustScan(ctx, client, "", "", 10, "k2", "v2")
+	t.mustScan(ctx, client, "", "", 1, "k2", "v2")
+}
+
+var _ = validator.RegisterTest("compare and swap", []string{"rawkv.get", "rawkv.put", "rawkv.cas"}, testRawKV{}.testCAS)
+
+func (t testRawKV) testCAS(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	err := client.SetAtomicForCAS(true)
+	ctx.AssertNil(err)
+
+	t.mustCAS(ctx, client, "k", "", "v1", true, true, "")
+	t.mustCAS(ctx, client, "k", "", "v2", true, false, "v1")
+	t.mustGet(ctx, client, "k", "v1")
+	t.mustCAS(ctx, client, "k", "v1", "v2", false, true, "v1")
+	t.mustCAS(ctx, client, "k", "v1", "v3", false, false, "v2")
+	t.mustGet(ctx, client, "k", "v2")
+
+	// Compare with a value while the key does not exist.
+	t.mustCAS(ctx, client, "k2", "v1", "v2", false, false, "")
+	t.mustNotExist(ctx, client, "k2")
+
+	// CAS should see writes by other operations in atomic mode.
+	t.mustPut(ctx, client, "k", "v4")
+	t.mustCAS(ctx, client, "k", "v2", "v5", false, false, "v4")
+	t.mustCAS(ctx, client, "k", "v4", "v5", false, true, "v4")
+	t.mustDelete(ctx, client, "k")
+	t.mustCAS(ctx, client, "k", "", "v6", true, true, "")
+	t.mustGet(ctx, client, "k", "v6")
+}
+
+var _ = validator.RegisterTest("compare and swap requires atomic mode", []string{"rawkv.cas"}, testRawKV{}.testCASNotAtomic)
+
+func (t testRawKV) testCASNotAtomic(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	_, _, err := client.CompareAndSwap([]byte("k"), nil, []byte("v"), true)
+	ctx.AssertNotNil(err, "compare and swap should fail if atomic mode is not enabled")
+}
+
+var _ = validator.RegisterTest("concurrent compare and swap counter", []string{"rawkv.get", "rawkv.cas"}, testRawKV{}.testCASCounter)
+
+func (t testRawKV) testCASCounter(ctx validator.ExecContext) {
+	const clients, increments = 8, 20
+
+	cluster := t.newCluster(ctx)
+	defer cluster.Close()
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, clients)
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errCh <- t.increaseCounter(cluster, []byte("counter"), increments)
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		ctx.AssertNil(err)
+	}
+
+	client, err := newRawClient(cluster.PDAddrs())
+	ctx.AssertNil(err)
+	defer client.Close()
+	t.mustGet(ctx, client, "counter", strconv.Itoa(clients*increments))
+}
+
+// increaseCounter increases the counter stored in key n times with a new
+// client, using compare-and-swap to detect concurrent updates. It gives up
+// after n*100 attempts, so that a client which never swaps does not hang.
+func (t testRawKV) increaseCounter(cluster *mocktikv.Cluster, key []byte, n int) error {
+	client, err := newRawClient(cluster.PDAddrs())
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err = client.SetAtomicForCAS(true); err != nil {
+		return err
+	}
+
+	var prev []byte
+	for i, attempts := 0, 0; i < n; attempts++ {
+		if attempts >= n*100 {
+			return errors.Errorf("counter is increased %d times after %d attempts", i, attempts)
+		}
+		var cnt int
+		if prev != nil {
+			if cnt, err = strconv.Atoi(string(prev)); err != nil {
+				return err
+			}
+		}
+		prevValue, ok, err := client.CompareAndSwap(key, prev, []byte(strconv.Itoa(cnt+1)), prev == nil)
+		if err != nil {
+			return err
+		}
+		if ok {
+			prev = []byte(strconv.Itoa(cnt + 1))
+			i++
+		} else {
+			prev = prevValue
+		}
+	}
+	return nil
+}
+
+// rawColumnFamilies are the column families that can be used in raw mod.
+var rawColumnFamilies = []string{"default", "write", "lock"}
+
+var _ = validator.RegisterTest("same key in different column families is isolated", []string{"rawkv.batch-get", "rawkv.batch-put", "rawkv.delete", "rawkv.column-family"}, testRawKV{}.testColumnFamilyIsolation)
+
+func (t testRawKV) testColumnFamilyIsolation(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	for _, cf := range rawColumnFamilies {
+		t.mustPut(ctx, client.WithCF(cf), "k", "v-"+cf)
+		t.mustBatchPut(ctx, client.WithCF(cf), []string{"k1", "k2"}, []string{"v1-" + cf, "v2-" + cf})
+	}
+	for _, cf := range rawColumnFamilies {
+		t.mustGet(ctx, client.WithCF(cf), "k", "v-"+cf)
+		t.mustBatchGet(ctx, client.WithCF(cf), []string{"k1", "k2"}, []string{"v1-" + cf, "v2-" + cf})
+	}
+
+	t.mustDelete(ctx, client.WithCF("write"), "k")
+	t.mustNotExist(ctx, client.WithCF("write"), "k")
+	t.mustGet(ctx, client.WithCF("default"), "k", "v-default")
+	t.mustGet(ctx, client.WithCF("lock"), "k", "v-lock")
+
+	// Empty column family means default, and the client without a view uses
+	// the default column family too.
+	t.mustGet(ctx, client.WithCF(""), "k", "v-default")
+	t.mustGet(ctx, client, "k", "v-default")
+}
+
+var _ = validator.RegisterTest("scan and delete range respect column family", []string{"rawkv.scan", "rawkv.delete-range", "rawkv.column-family"}, testRawKV{}.testColumnFamilyRange)
+
+func (t testRawKV) testColumnFamilyRange(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	def, write, lock := client.WithCF("default"), client.WithCF("write"), client.WithCF("lock")
+	t.mustPut(ctx, def, "k1", "v1")
+	t.mustPut(ctx, def, "k3", "v3")
+	t.mustPut(ctx, write, "k2", "v2")
+	t.mustPut(ctx, write, "k3", "w3")
+	t.mustPut(ctx, lock, "k4", "v4")
+
+	check := func() {
+		t.mustScan(ctx, def, "", "", 10, "k1", "v1", "k3", "v3")
+		t.mustScan(ctx, write, "", "", 10, "k2", "v2", "k3", "w3")
+		t.mustScan(ctx, lock, "", "", 10, "k4", "v4")
+	}
+	check()
+	mustSplit(ctx, cluster, "k", "k3")
+	check()
+
+	t.mustDeleteRange(ctx, write, "k", "l")
+	t.mustScan(ctx, write, "", "", 10)
+	t.mustScan(ctx, def, "", "", 10, "k1", "v1", "k3", "v3")
+	t.mustScan(ctx, lock, "", "", 10, "k4", "v4")
+}
+
 var _ = validator.RegisterTest("delete range", []string{"rawkv.batch-put", "rawkv.scan", "rawkv.delete-range"}, testRawKV{}.testDeleteRange)
 
 func (t testRawKV) testDeleteRange(ctx validator.ExecContext) {
@@ -326,7 +816,7 @@ func (t testRawKV) testDeleteRange(ctx validator.ExecContext) {
 	check("a", "z")
 }
 
-func (t testRawKV) mustNotExist(ctx validator.ExecContext, client *stub.RawClientStub, key string) {
+func (t testRawKV) mustNotExist(ctx validator.ExecContext, client stub.RawKV, key string) {
 	ctx.AddCallerDepth(1)
 	defer ctx.AddCallerDepth(-1)
 	v, err := client.Get([]byte(key))
@@ -334,7 +824,7 @@ func (t testRawKV) mustNotExist(ctx validator.ExecContext, client *stub.RawClien
 	ctx.AssertEQ(len(v), 0)
 }
 
-func (t testRawKV) mustBatchNotExist(ctx validator.ExecContext, client *stub.RawClientStub, keys []string) {
+func (t testRawKV) mustBatchNotExist(ctx validator.ExecContext, client stub.RawKV, keys []string) {
 	ctx.AddCallerDepth(1)
 	defer ctx.AddCallerDepth(-1)
 	values, err := client.BatchGet(bss(keys...))
@@ -345,7 +835,7 @@ func (t testRawKV) mustBatchNotExist(ctx validator.ExecContext, client *stub.Raw
 	}
 }
 
-func (t testRawKV) mustGet(ctx validator.ExecContext, client *stub.RawClientStub, key, value string) {
+func (t testRawKV) mustGet(ctx validator.ExecContext, client stub.RawKV, key, value string) {
 	ctx.AddCallerDepth(1)
 	defer ctx.AddCallerDepth(-1)
 	val, err := client.Get([]byte(key))
@@ -353,7 +843,7 @@ func (t testRawKV) mustGet(ctx validator.ExecContext, client *stub.RawClientStub
 	ctx.AssertEQ(string(val), value)
 }
 
-func (t testRawKV) mustBatchGet(ctx validator.ExecContext, client *stub.RawClientStub, keys, values []string) {
+func (t testRawKV) mustBatchGet(ctx validator.ExecContext, client stub.RawKV, keys, values []string) {
 	ctx.AddCallerDepth(1)
 	defer ctx.AddCallerDepth(-1)
 	vals, err := client.BatchGet(bss(keys...))
@@ -364,35 +854,68 @@ func (t testRawKV) mustBatchGet(ctx validator.ExecContext, client *stub.RawClien
 	}
 }
 
-func (t testRawKV) mustPut(ctx validator.ExecContext, client *stub.RawClientStub, key, value string) {
+func (t testRawKV) mustPut(ctx validator.ExecContext, client stub.RawKV, key, value string) {
 	ctx.AddCallerDepth(1)
 	defer ctx.AddCallerDepth(-1)
 	err := client.Put([]byte(key), []byte(value))
 	ctx.AssertNil(err)
 }
 
-func (t testRawKV) mustBatchPut(ctx validator.ExecContext, client *stub.RawClientStub, keys, values []string) {
+func (t testRawKV) mustPutWithTTL(ctx validator.ExecContext, client stub.RawKV, key, value string, ttl uint64) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	err := client.PutWithTTL([]byte(key), []byte(value), ttl)
+	ctx.AssertNil(err)
+}
+
+func (t testRawKV) mustKeyTTL(ctx validator.ExecContext, client stub.RawKV, key string, min, max uint64) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	ttl, err := client.GetKeyTTL([]byte(key))
+	ctx.AssertNil(err)
+	ctx.Assert(ttl != nil, "expect ttl of existing key")
+	ctx.Assert(*ttl >= min && *ttl <= max, fmt.Sprintf("expect ttl in [%v, %v], got %v", min, max, *ttl))
+}
+
+func (t testRawKV) mustNoKeyTTL(ctx validator.ExecContext, client stub.RawKV, key string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	ttl, err := client.GetKeyTTL([]byte(key))
+	ctx.AssertNil(err)
+	ctx.Assert(ttl == nil, "expect no ttl for not existing key")
+}
+
+func (t testRawKV) mustCAS(ctx validator.ExecContext, client stub.RawKV, key, prevValue, newValue string, prevNotExist, expectSucceed bool, expectPrev string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	prev, ok, err := client.CompareAndSwap([]byte(key), []byte(prevValue), []byte(newValue), prevNotExist)
+	ctx.AssertNil(err)
+	ctx.AssertEQ(ok, expectSucceed)
+	ctx.AssertEQ(string(prev), expectPrev)
+}
+
+func (t testRawKV) mustBatchPut(ctx validator.ExecContext, client stub.RawKV, keys, values []string) {
 	ctx.AddCallerDepth(1)
 	defer ctx.AddCallerDepth(-1)
 	err := client.BatchPut(bss(keys...), bss(values...))
 	ctx.AssertNil(err)
 }
 
-func (t testRawKV) mustDelete(ctx validator.ExecContext, client *stub.RawClientStub, key string) {
+func (t testRawKV) mustDelete(ctx validator.ExecContext, client stub.RawKV, key string) {
 	ctx.AddCallerDepth(1)
 	defer ctx.AddCallerDepth(-1)
 	err := client.Delete([]byte(key))
 	ctx.AssertNil(err)
 }
 
-func (t testRawKV) mustBatchDelete(ctx validator.ExecContext, client *stub.RawClientStub, keys []string) {
+func (t testRawKV) mustBatchDelete(ctx validator.ExecContext, client stub.RawKV, keys []string) {
 	ctx.AddCallerDepth(1)
 	defer ctx.AddCallerDepth(-1)
 	err := client.BatchDelete(bss(keys...))
 	ctx.AssertNil(err)
 }
 
-func (t testRawKV) mustScan(ctx validator.ExecContext, client *stub.RawClientStub, start, end string, limit int, expect ...string) {
+func (t testRawKV) mustScan(ctx validator.ExecContext, client stub.RawKV, start, end string, limit int, expect ...string) {
 	ctx.AddCallerDepth(1)
 	defer ctx.AddCallerDepth(-1)
 	keys, values, err := client.Scan([]byte(start), []byte(end), limit)
@@ -404,21 +927,35 @@ func (t testRawKV) mustScan(ctx validator.ExecContext, client *stub.RawClientStu
 	}
 }
 
-func (t testRawKV) mustDeleteRange(ctx validator.ExecContext, client *stub.RawClientStub, start, end string) {
+func (t testRawKV) mustReverseScan(ctx validator.ExecContext, client stub.RawKV, start, end string, limit int, expect ...string) {
 	ctx.AddCallerDepth(1)
 	defer ctx.AddCallerDepth(-1)
-	err := client.DeleteRange([]byte(start), []byte(end))
+	keys, values, err := client.ReverseScan([]byte(start), []byte(end), limit)
 	ctx.AssertNil(err)
+	ctx.AssertEQ(len(keys)*2, len(expect))
+	for i := range keys {
+		ctx.AssertEQ(string(keys[i]), expect[i*2])
+		ctx.AssertEQ(string(values[i]), expect[i*2+1])
+	}
 }
 
-func (t testRawKV) mustSplit(ctx validator.ExecContext, cluster *mocktikv.Cluster, start, end string) {
-	// TODO: Not supported by mock-tikv now.
+func (t testRawKV) mustScanKeyOnly(ctx validator.ExecContext, client stub.RawKV, start, end string, limit int, expect ...string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	keys, values, err := client.ScanKeyOnly([]byte(start), []byte(end), limit)
+	ctx.AssertNil(err)
+	ctx.AssertEQ(len(keys), len(expect))
+	for i := range keys {
+		ctx.AssertEQ(string(keys[i]), expect[i])
+	}
+	for i, v := range values {
+		ctx.Assert(len(v) == 0, fmt.Sprintf("expect no value at %d in key-only scan, got %q", i, v))
+	}
 }
 
-func bss(ss ...string) [][]byte {
-	bss := make([][]byte, len(ss))
-	for i := range ss {
-		bss[i] = []byte(ss[i])
-	}
-	return bss
+func (t testRawKV) mustDeleteRange(ctx validator.ExecContext, client stub.RawKV, start, end string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	err := client.DeleteRange([]byte(start), []byte(end))
+	ctx.AssertNil(err)
 }
@@ -0,0 +1,329 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"fmt"
+
+	"github.com/tikv/client-validator/mocktikv"
+	"github.com/tikv/client-validator/stub"
+	"github.com/tikv/client-validator/validator"
+)
+
+// Tests in this file change regions behind the client's back, after the client
+// caches them. The client should recover on the next operation without
+// returning errors, and refresh its region cache without storming PD.
+
+// regionChanges are applied in order to the region containing a key. Split
+// makes the key a region boundary, merge removes the boundary, and move puts
+// the region on other stores.
+var regionChanges = []struct {
+	name   string
+	change func(cluster *mocktikv.Cluster, key []byte) error
+}{
+	{"split", func(cluster *mocktikv.Cluster, key []byte) error { return cluster.Split(key) }},
+	{"merge", (*mocktikv.Cluster).Merge},
+	{"move", (*mocktikv.Cluster).MoveRegion},
+}
+
+// staleRegionKVs are the keys used by stale region tests, they are in the same
+// region before it is split at the middle key.
+func staleRegionKVs() (keys, values []string, middle string) {
+	keys, values = seqKVs(10, 8)
+	return keys, values, keys[len(keys)/2]
+}
+
+// mustRecoverFromStaleRegions calls op to fill the region cache, changes the
+// region containing key, and calls op again. op should check its result. The
+// region cache should be refreshed by at most lookups requests to PD, i.e.
+// once for each region op accesses.
+func mustRecoverFromStaleRegions(ctx validator.ExecContext, cluster *mocktikv.Cluster, key string, lookups uint64, op func()) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	// Surround the keys with other regions, so that the changed region has
+	// neighbors on both sides.
+	mustSplit(ctx, cluster, "k", "l")
+	for _, c := range regionChanges {
+		op()
+		err := c.change(cluster, []byte(key))
+		ctx.AssertNil(err, c.name)
+		mustResetStats(ctx, cluster)
+		op()
+		stats := mustStats(ctx, cluster)
+		got := stats.RegionLookups()
+		ctx.Assert(got <= lookups, fmt.Sprintf("after %s, expect at most %d region lookups, got %d: %v", c.name, lookups, got, stats.PDCommands))
+	}
+}
+
+func (t testRawKV) newStaleRegionClient(ctx validator.ExecContext) (*mocktikv.Cluster, stub.RawKV) {
+	cluster, client := t.newClient(ctx)
+	keys, values, _ := staleRegionKVs()
+	mustLoad(ctx, cluster, mocktikv.StorageRaw, zipKVs(keys, values)...)
+	return cluster, client
+}
+
+var _ = validator.RegisterTest("rawkv get recovers from stale region cache", []string{"rawkv.get"}, testRawKV{}.testStaleRegionGet)
+
+func (t testRawKV) testStaleRegionGet(ctx validator.ExecContext) {
+	cluster, client := t.newStaleRegionClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values, middle := staleRegionKVs()
+	i := len(keys) - 1
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 1, func() {
+		t.mustGet(ctx, client, keys[i], values[i])
+	})
+}
+
+var _ = validator.RegisterTest("rawkv batch get recovers from stale region cache", []string{"rawkv.batch-get"}, testRawKV{}.testStaleRegionBatchGet)
+
+func (t testRawKV) testStaleRegionBatchGet(ctx validator.ExecContext) {
+	cluster, client := t.newStaleRegionClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values, middle := staleRegionKVs()
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 2, func() {
+		t.mustBatchGet(ctx, client, keys, values)
+	})
+}
+
+var _ = validator.RegisterTest("rawkv put recovers from stale region cache", []string{"rawkv.put"}, testRawKV{}.testStaleRegionPut)
+
+func (t testRawKV) testStaleRegionPut(ctx validator.ExecContext) {
+	cluster, client := t.newStaleRegionClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values, middle := staleRegionKVs()
+	i := len(keys) - 1
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 1, func() {
+		t.mustPut(ctx, client, keys[i], "v")
+	})
+	values[i] = "v"
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", zipKVs(keys, values)...)
+}
+
+var _ = validator.RegisterTest("rawkv batch put recovers from stale region cache", []string{"rawkv.batch-put"}, testRawKV{}.testStaleRegionBatchPut)
+
+func (t testRawKV) testStaleRegionBatchPut(ctx validator.ExecContext) {
+	cluster, client := t.newStaleRegionClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values, middle := staleRegionKVs()
+	for i := range values {
+		values[i] = "v" + values[i]
+	}
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 2, func() {
+		t.mustBatchPut(ctx, client, keys, values)
+	})
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", zipKVs(keys, values)...)
+}
+
+var _ = validator.RegisterTest("rawkv put with TTL recovers from stale region cache", []string{"rawkv.put-ttl"}, testRawKV{}.testStaleRegionPutWithTTL)
+
+func (t testRawKV) testStaleRegionPutWithTTL(ctx validator.ExecContext) {
+	cluster, client := t.newStaleRegionClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values, middle := staleRegionKVs()
+	i := len(keys) - 1
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 1, func() {
+		t.mustPutWithTTL(ctx, client, keys[i], "v", 100)
+	})
+	values[i] = "v"
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", zipKVs(keys, values)...)
+}
+
+var _ = validator.RegisterTest("rawkv batch put with TTL recovers from stale region cache", []string{"rawkv.batch-put-ttl"}, testRawKV{}.testStaleRegionBatchPutWithTTL)
+
+func (t testRawKV) testStaleRegionBatchPutWithTTL(ctx validator.ExecContext) {
+	cluster, client := t.newStaleRegionClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values, middle := staleRegionKVs()
+	ttls := make([]uint64, len(keys))
+	for i := range values {
+		values[i] = "v" + values[i]
+		ttls[i] = 100
+	}
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 2, func() {
+		err := client.BatchPutWithTTL(bss(keys...), bss(values...), ttls)
+		ctx.AssertNil(err)
+	})
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", zipKVs(keys, values)...)
+}
+
+var _ = validator.RegisterTest("rawkv get key TTL recovers from stale region cache", []string{"rawkv.get-key-ttl"}, testRawKV{}.testStaleRegionGetKeyTTL)
+
+func (t testRawKV) testStaleRegionGetKeyTTL(ctx validator.ExecContext) {
+	cluster, client := t.newStaleRegionClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, _, middle := staleRegionKVs()
+	i := len(keys) - 1
+	t.mustPutWithTTL(ctx, client, keys[i], "v", 100)
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 1, func() {
+		t.mustKeyTTL(ctx, client, keys[i], 1, 100)
+	})
+}
+
+var _ = validator.RegisterTest("rawkv compare and swap recovers from stale region cache", []string{"rawkv.cas"}, testRawKV{}.testStaleRegionCAS)
+
+func (t testRawKV) testStaleRegionCAS(ctx validator.ExecContext) {
+	cluster, client := t.newStaleRegionClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	err := client.SetAtomicForCAS(true)
+	ctx.AssertNil(err)
+	keys, values, middle := staleRegionKVs()
+	i := len(keys) - 1
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 1, func() {
+		t.mustCAS(ctx, client, keys[i], values[i], values[i], false, true, values[i])
+	})
+}
+
+var _ = validator.RegisterTest("rawkv delete recovers from stale region cache", []string{"rawkv.delete"}, testRawKV{}.testStaleRegionDelete)
+
+func (t testRawKV) testStaleRegionDelete(ctx validator.ExecContext) {
+	cluster, client := t.newStaleRegionClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values, middle := staleRegionKVs()
+	i := len(keys) - 1
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 1, func() {
+		t.mustDelete(ctx, client, keys[i])
+	})
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", zipKVs(keys[:i], values[:i])...)
+}
+
+var _ = validator.RegisterTest("rawkv batch delete recovers from stale region cache", []string{"rawkv.batch-delete"}, testRawKV{}.testStaleRegionBatchDelete)
+
+func (t testRawKV) testStaleRegionBatchDelete(ctx validator.ExecContext) {
+	cluster, client := t.newStaleRegionClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, _, middle := staleRegionKVs()
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 2, func() {
+		t.mustBatchDelete(ctx, client, keys)
+	})
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "")
+}
+
+var _ = validator.RegisterTest("rawkv delete range recovers from stale region cache", []string{"rawkv.delete-range"}, testRawKV{}.testStaleRegionDeleteRange)
+
+func (t testRawKV) testStaleRegionDeleteRange(ctx validator.ExecContext) {
+	cluster, client := t.newStaleRegionClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values, middle := staleRegionKVs()
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 2, func() {
+		t.mustDeleteRange(ctx, client, keys[1], keys[len(keys)-1])
+	})
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", keys[0], values[0], keys[len(keys)-1], values[len(keys)-1])
+}
+
+var _ = validator.RegisterTest("rawkv scan recovers from stale region cache", []string{"rawkv.scan"}, testRawKV{}.testStaleRegionScan)
+
+func (t testRawKV) testStaleRegionScan(ctx validator.ExecContext) {
+	cluster, client := t.newStaleRegionClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values, middle := staleRegionKVs()
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 2, func() {
+		t.mustScan(ctx, client, "k", "l", 100, zipKVs(keys, values)...)
+	})
+}
+
+var _ = validator.RegisterTest("rawkv reverse scan recovers from stale region cache", []string{"rawkv.reverse-scan"}, testRawKV{}.testStaleRegionReverseScan)
+
+func (t testRawKV) testStaleRegionReverseScan(ctx validator.ExecContext) {
+	cluster, client := t.newStaleRegionClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values, middle := staleRegionKVs()
+	var expect []string
+	for i := len(keys) - 1; i >= 0; i-- {
+		expect = append(expect, keys[i], values[i])
+	}
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 2, func() {
+		t.mustReverseScan(ctx, client, "l", "k", 100, expect...)
+	})
+}
+
+var _ = validator.RegisterTest("rawkv key-only scan recovers from stale region cache", []string{"rawkv.scan-key-only"}, testRawKV{}.testStaleRegionScanKeyOnly)
+
+func (t testRawKV) testStaleRegionScanKeyOnly(ctx validator.ExecContext) {
+	cluster, client := t.newStaleRegionClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, _, middle := staleRegionKVs()
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 2, func() {
+		t.mustScanKeyOnly(ctx, client, "k", "l", 100, keys...)
+	})
+}
+
+var _ = validator.RegisterTest("txnkv get recovers from stale region cache", []string{"txnkv.get"}, testTxnKV{}.testStaleRegionGet)
+
+func (t testTxnKV) testStaleRegionGet(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values, middle := staleRegionKVs()
+	mustLoad(ctx, cluster, mocktikv.StorageTxn, zipKVs(keys, values)...)
+	i := len(keys) - 1
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 1, func() {
+		txn := t.mustBegin(ctx, client)
+		t.mustGet(ctx, txn, keys[i], values[i])
+		t.mustRollback(ctx, txn)
+	})
+}
+
+var _ = validator.RegisterTest("txnkv commit recovers from stale region cache", []string{"txnkv.commit"}, testTxnKV{}.testStaleRegionCommit)
+
+// testStaleRegionCommit writes keys on both sides of the split key, so that
+// both prewrite and commit are sent to the changed regions.
+func (t testTxnKV) testStaleRegionCommit(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values, middle := staleRegionKVs()
+	round := 0
+	mustRecoverFromStaleRegions(ctx, cluster, middle, 2, func() {
+		round++
+		txn := t.mustBegin(ctx, client)
+		for i := range keys {
+			t.mustSet(ctx, txn, keys[i], fmt.Sprintf("%s-%d", values[i], round))
+		}
+		t.mustCommit(ctx, txn)
+	})
+	for i := range values {
+		values[i] = fmt.Sprintf("%s-%d", values[i], round)
+	}
+	mustDump(ctx, cluster, mocktikv.StorageTxn, "", "", zipKVs(keys, values)...)
+}
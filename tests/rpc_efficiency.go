@@ -0,0 +1,122 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"github.com/tikv/client-validator/mocktikv"
+	"github.com/tikv/client-validator/validator"
+)
+
+// Tests in this file check requests received by mock-tikv instead of results
+// returned by the client. A client sending more requests than needed works,
+// but it is a defect.
+
+var _ = validator.RegisterTest("rawkv batch get in one region sends one request", []string{"rawkv.batch-get"}, testRawKV{}.testBatchGetRequests)
+
+func (t testRawKV) testBatchGetRequests(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := seqKVs(100, 8)
+	mustLoad(ctx, cluster, mocktikv.StorageRaw, zipKVs(keys, values)...)
+	mustResetStats(ctx, cluster)
+	t.mustBatchGet(ctx, client, keys, values)
+	stats := mustStats(ctx, cluster)
+	mustCommandsAtMost(ctx, stats, "RawBatchGet", 1)
+	mustCommandsAtMost(ctx, stats, "RawGet", 0)
+	mustNoRegionErrors(ctx, stats)
+}
+
+var _ = validator.RegisterTest("rawkv batch put sends one request per region", []string{"rawkv.batch-put"}, testRawKV{}.testBatchPutRequests)
+
+func (t testRawKV) testBatchPutRequests(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := seqKVs(100, 8)
+	mustSplitEvenly(ctx, cluster, keys, 4)
+	mustResetStats(ctx, cluster)
+	t.mustBatchPut(ctx, client, keys, values)
+	stats := mustStats(ctx, cluster)
+	mustCommandsAtMost(ctx, stats, "RawBatchPut", 4)
+	mustCommandsAtMost(ctx, stats, "RawPut", 0)
+	mustNoRegionErrors(ctx, stats)
+	mustDump(ctx, cluster, mocktikv.StorageRaw, "", "", zipKVs(keys, values)...)
+}
+
+var _ = validator.RegisterTest("rawkv requests are routed without region errors", []string{"rawkv.get", "rawkv.put", "rawkv.scan"}, testRawKV{}.testRouteRequests)
+
+// testRouteRequests checks that the client sends requests to the right region
+// leaders of a stable cluster, so no region error is returned.
+func (t testRawKV) testRouteRequests(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := seqKVs(20, 8)
+	mustSplitEvenly(ctx, cluster, keys, 4)
+	mustResetStats(ctx, cluster)
+	for i := range keys {
+		t.mustPut(ctx, client, keys[i], values[i])
+	}
+	for i := range keys {
+		t.mustGet(ctx, client, keys[i], values[i])
+	}
+	t.mustScan(ctx, client, "", "", 100, zipKVs(keys, values)...)
+	stats := mustStats(ctx, cluster)
+	mustCommandsAtMost(ctx, stats, "RawPut", uint64(len(keys)))
+	mustCommandsAtMost(ctx, stats, "RawGet", uint64(len(keys)))
+	mustNoRegionErrors(ctx, stats)
+}
+
+var _ = validator.RegisterTest("txnkv batch get in one region sends one request", []string{"txnkv.batch-get"}, testTxnKV{}.testBatchGetRequests)
+
+func (t testTxnKV) testBatchGetRequests(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := seqKVs(100, 8)
+	mustLoad(ctx, cluster, mocktikv.StorageTxn, zipKVs(keys, values)...)
+	txn := t.mustBegin(ctx, client)
+	mustResetStats(ctx, cluster)
+	t.mustBatchGet(ctx, txn, keys, values)
+	stats := mustStats(ctx, cluster)
+	mustCommandsAtMost(ctx, stats, "KvBatchGet", 1)
+	mustCommandsAtMost(ctx, stats, "KvGet", 0)
+	mustNoRegionErrors(ctx, stats)
+}
+
+var _ = validator.RegisterTest("txnkv commit in one region sends one prewrite and one commit", []string{"txnkv.commit"}, testTxnKV{}.testCommitRequests)
+
+func (t testTxnKV) testCommitRequests(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys, values := seqKVs(100, 8)
+	txn := t.mustBegin(ctx, client)
+	for i := range keys {
+		t.mustSet(ctx, txn, keys[i], values[i])
+	}
+	mustResetStats(ctx, cluster)
+	t.mustCommit(ctx, txn)
+	stats := mustStats(ctx, cluster)
+	mustCommandsAtMost(ctx, stats, "KvPrewrite", 1)
+	mustCommandsAtMost(ctx, stats, "KvCommit", 1)
+	mustNoRegionErrors(ctx, stats)
+	mustDump(ctx, cluster, mocktikv.StorageTxn, "", "", zipKVs(keys, values)...)
+}
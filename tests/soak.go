@@ -0,0 +1,279 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tikv/client-validator/mocktikv"
+	"github.com/tikv/client-validator/stub"
+	"github.com/tikv/client-validator/validator"
+)
+
+var (
+	soakFaultInterval = flag.Duration("soak-fault-interval", 10*time.Second, "interval of faults injected by soaks")
+	soakCheckInterval = flag.Duration("soak-check-interval", 5*time.Second, "interval of consistency checks by soaks")
+)
+
+const (
+	soakRawWorkers  = 4
+	soakRawKeys     = 100 // per raw worker
+	soakTxnWorkers  = 4
+	soakAccounts    = 20
+	soakBalance     = 1000 // initial balance of each account
+	soakMaxTransfer = 100
+)
+
+var _ = validator.RegisterSoak("mixed", []string{"rawkv.put", "rawkv.get", "txnkv.begin", "txnkv.batch-get", "txnkv.set", "txnkv.commit"}, soakMixed)
+
+// soakMixed runs rawkv and txnkv workloads together, while regions are split,
+// leaders are transferred and PD fails over periodically. Rawkv workers check
+// that each key reads the value written last, and txnkv workers transfer money
+// between accounts while the total balance is checked.
+func soakMixed(ctx validator.SoakContext) {
+	cluster, err := mocktikv.NewCluster(*mockTiKVAddr)
+	ctx.AssertNil(err)
+	defer cluster.Close()
+	rawClient, err := newRawClient(cluster.PDAddrs())
+	ctx.AssertNil(err)
+	defer rawClient.Close()
+	txnClient, err := newTxnClient(cluster.PDAddrs())
+	ctx.AssertNil(err)
+	defer txnClient.Close()
+
+	var accounts []string
+	for i := 0; i < soakAccounts; i++ {
+		accounts = append(accounts, fmt.Sprintf("acct-%02d", i), strconv.Itoa(soakBalance))
+	}
+	mustLoad(ctx, cluster, mocktikv.StorageTxn, accounts...)
+
+	// Workers run in goroutines, each has its own random source derived from
+	// the context, and reports to the context instead of asserting.
+	var wg sync.WaitGroup
+	run := func(f func(r *rand.Rand)) {
+		r := rand.New(rand.NewSource(ctx.Rand().Int63()))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f(r)
+		}()
+	}
+	for i := 0; i < soakRawWorkers; i++ {
+		prefix := fmt.Sprintf("raw-%d-", i)
+		run(func(r *rand.Rand) { soakRawWorker(ctx, rawClient, prefix, r) })
+	}
+	for i := 0; i < soakTxnWorkers; i++ {
+		run(func(r *rand.Rand) { soakTransferWorker(ctx, txnClient, r) })
+	}
+	run(func(*rand.Rand) {
+		for soakSleep(ctx, *soakCheckInterval) {
+			soakCheckBalance(ctx, txnClient)
+		}
+	})
+	run(func(r *rand.Rand) {
+		for soakSleep(ctx, *soakFaultInterval) {
+			soakInjectFault(ctx, cluster, r)
+		}
+	})
+	wg.Wait()
+
+	// The cluster should recover from all faults at the end.
+	if err := soakCheckBalance(ctx, txnClient); err != nil {
+		ctx.Anomaly("txnkv check fails after faults stop: %v", err)
+	}
+}
+
+// soakRawWorker writes increasing values to keys with the prefix and reads
+// them back. A failed put may or may not be applied, so all values written
+// since the last successful read are possible.
+func soakRawWorker(ctx validator.SoakContext, client stub.RawKV, prefix string, r *rand.Rand) {
+	possible := make(map[string][]string)
+	next := 0
+	for !soakDone(ctx) {
+		key := fmt.Sprintf("%s%03d", prefix, r.Intn(soakRawKeys))
+		if _, ok := possible[key]; !ok {
+			possible[key] = []string{""}
+		}
+		next++
+		value := strconv.Itoa(next)
+		start := time.Now()
+		err := client.Put([]byte(key), []byte(value))
+		ctx.Record("rawkv.put", time.Since(start), err)
+		if err == nil {
+			possible[key] = []string{value}
+		} else {
+			possible[key] = append(possible[key], value)
+		}
+
+		start = time.Now()
+		got, err := client.Get([]byte(key))
+		ctx.Record("rawkv.get", time.Since(start), err)
+		if err != nil {
+			continue
+		}
+		if !containsString(possible[key], string(got)) {
+			ctx.Anomaly("rawkv key %q: expect one of %q, got %q", key, possible[key], got)
+		}
+		possible[key] = []string{string(got)}
+	}
+}
+
+// soakTransferWorker moves random amounts between random accounts. Write
+// conflicts are expected, and failed transactions are not retried.
+func soakTransferWorker(ctx validator.SoakContext, client stub.TxnKV, r *rand.Rand) {
+	for !soakDone(ctx) {
+		from, to := r.Intn(soakAccounts), r.Intn(soakAccounts)
+		if from == to {
+			continue
+		}
+		keys := bss(fmt.Sprintf("acct-%02d", from), fmt.Sprintf("acct-%02d", to))
+		start := time.Now()
+		err := soakTransfer(client, keys, r.Intn(soakMaxTransfer)+1)
+		ctx.Record("txnkv.transfer", time.Since(start), err)
+	}
+}
+
+func soakTransfer(client stub.TxnKV, keys [][]byte, amount int) (err error) {
+	txn, err := client.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			txn.Rollback()
+		}
+	}()
+	m, err := txn.BatchGet(keys)
+	if err != nil {
+		return err
+	}
+	var balances [2]int
+	for i, k := range keys {
+		if balances[i], err = strconv.Atoi(string(m[string(k)])); err != nil {
+			return err
+		}
+	}
+	if err = txn.Set(keys[0], []byte(strconv.Itoa(balances[0]-amount))); err != nil {
+		return err
+	}
+	if err = txn.Set(keys[1], []byte(strconv.Itoa(balances[1]+amount))); err != nil {
+		return err
+	}
+	return txn.Commit()
+}
+
+// soakCheckBalance reads all accounts in a snapshot, the total balance should
+// never change. It returns the error of reading, which is expected while faults
+// are injected.
+func soakCheckBalance(ctx validator.SoakContext, client stub.TxnKV) error {
+	var keys []string
+	for i := 0; i < soakAccounts; i++ {
+		keys = append(keys, fmt.Sprintf("acct-%02d", i))
+	}
+	start := time.Now()
+	m, err := soakReadAll(client, bss(keys...))
+	ctx.Record("txnkv.check", time.Since(start), err)
+	if err != nil {
+		return err
+	}
+	total := 0
+	for _, k := range keys {
+		v, ok := m[k]
+		if !ok {
+			ctx.Anomaly("txnkv account %q is lost", k)
+			return nil
+		}
+		balance, err := strconv.Atoi(string(v))
+		if err != nil {
+			ctx.Anomaly("txnkv account %q has invalid balance %q", k, v)
+			return nil
+		}
+		total += balance
+	}
+	if total != soakAccounts*soakBalance {
+		ctx.Anomaly("txnkv total balance: expect %d, got %d", soakAccounts*soakBalance, total)
+	}
+	return nil
+}
+
+func soakReadAll(client stub.TxnKV, keys [][]byte) (map[string][]byte, error) {
+	txn, err := client.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer txn.Rollback()
+	return txn.BatchGet(keys)
+}
+
+// soakInjectFault splits a region, transfers a region leader, or fails over
+// PD, at random.
+func soakInjectFault(ctx validator.SoakContext, cluster *mocktikv.Cluster, r *rand.Rand) {
+	var key string
+	if r.Intn(2) == 0 {
+		key = fmt.Sprintf("raw-%d-%03d", r.Intn(soakRawWorkers), r.Intn(soakRawKeys))
+	} else {
+		key = fmt.Sprintf("acct-%02d", r.Intn(soakAccounts))
+	}
+	var fault string
+	var err error
+	switch r.Intn(3) {
+	case 0:
+		fault = fmt.Sprintf("split at %q", key)
+		err = cluster.Split([]byte(key))
+	case 1:
+		fault = fmt.Sprintf("transfer leader of %q", key)
+		err = cluster.TransferLeader([]byte(key))
+	default:
+		fault = "failover pd"
+		err = cluster.FailoverPD()
+	}
+	if err != nil {
+		ctx.Event("%s: %v", fault, err)
+		return
+	}
+	ctx.Event("%s", fault)
+}
+
+// soakSleep waits for d, it returns false if the soak is done.
+func soakSleep(ctx validator.SoakContext, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+func soakDone(ctx validator.SoakContext) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,350 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"github.com/tikv/client-validator/mocktikv"
+	"github.com/tikv/client-validator/stub"
+	"github.com/tikv/client-validator/validator"
+)
+
+type testTxnKV struct{}
+
+func (t testTxnKV) newCluster(ctx validator.ExecContext) *mocktikv.Cluster {
+	cluster, err := mocktikv.NewCluster(*mockTiKVAddr)
+	ctx.AssertNil(err)
+	return cluster
+}
+
+var _ = validator.RegisterFeature("txnkv.new", "create a txnkv client", nil, testTxnKV{}.checkClientCreate)
+
+func (t testTxnKV) checkClientCreate(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster := t.newCluster(ctx)
+	defer cluster.Close()
+	client, err := newTxnClient(cluster.PDAddrs())
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	defer client.Close()
+	return validator.FeaturePass
+}
+
+func (t testTxnKV) newClient(ctx validator.ExecContext) (*mocktikv.Cluster, stub.TxnKV) {
+	cluster := t.newCluster(ctx)
+	client := t.newClientWithCluster(ctx, cluster)
+	return cluster, client
+}
+
+// newClientWithCluster creates one more client of the cluster, it is used to
+// run transactions from different clients.
+func (t testTxnKV) newClientWithCluster(ctx validator.ExecContext, cluster *mocktikv.Cluster) stub.TxnKV {
+	client, err := newTxnClient(cluster.PDAddrs())
+	ctx.AssertNil(err)
+	return client
+}
+
+var _ = validator.RegisterFeature("txnkv.close", "close a txnkv client", nil, testTxnKV{}.checkClose)
+
+func (t testTxnKV) checkClose(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	err := client.Close()
+	return errToFeatureStatus(err)
+}
+
+var _ = validator.RegisterFeature("txnkv.begin", "begin a transaction", []string{"txnkv.new"}, testTxnKV{}.checkBegin)
+
+func (t testTxnKV) checkBegin(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	_, err := client.Begin()
+	return errToFeatureStatus(err)
+}
+
+var _ = validator.RegisterFeature("txnkv.get", "get value in a transaction", []string{"txnkv.begin"}, testTxnKV{}.checkGet)
+
+func (t testTxnKV) checkGet(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	mustLoad(ctx, cluster, mocktikv.StorageTxn, "k1", "v1")
+	txn := t.mustBegin(ctx, client)
+	val, err := txn.Get([]byte("k1"))
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	ctx.AssertEQ(string(val), "v1")
+	val, err = txn.Get([]byte("k2"))
+	ctx.AssertNil(err)
+	ctx.Assert(len(val) == 0, "expect empty value")
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterFeature("txnkv.set", "set value in a transaction", []string{"txnkv.begin"}, testTxnKV{}.checkSet)
+
+func (t testTxnKV) checkSet(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	txn := t.mustBegin(ctx, client)
+	err := txn.Set([]byte("k"), []byte("v"))
+	return errToFeatureStatus(err)
+}
+
+var _ = validator.RegisterFeature("txnkv.delete", "delete key in a transaction", []string{"txnkv.begin"}, testTxnKV{}.checkDelete)
+
+func (t testTxnKV) checkDelete(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	txn := t.mustBegin(ctx, client)
+	err := txn.Delete([]byte("k"))
+	return errToFeatureStatus(err)
+}
+
+var _ = validator.RegisterFeature("txnkv.commit", "commit a transaction", []string{"txnkv.set"}, testTxnKV{}.checkCommit)
+
+func (t testTxnKV) checkCommit(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	txn := t.mustBegin(ctx, client)
+	t.mustSet(ctx, txn, "k", "v")
+	err := txn.Commit()
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	mustDump(ctx, cluster, mocktikv.StorageTxn, "", "", "k", "v")
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterFeature("txnkv.rollback", "rollback a transaction", []string{"txnkv.set"}, testTxnKV{}.checkRollback)
+
+func (t testTxnKV) checkRollback(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	txn := t.mustBegin(ctx, client)
+	t.mustSet(ctx, txn, "k", "v")
+	err := txn.Rollback()
+	return errToFeatureStatus(err)
+}
+
+var _ = validator.RegisterFeature("txnkv.get-ts", "get a timestamp from PD", []string{"txnkv.new"}, testTxnKV{}.checkGetTS)
+
+func (t testTxnKV) checkGetTS(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	ts, err := client.GetTS()
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	ctx.Assert(ts > 0, "expect positive timestamp")
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterFeature("txnkv.batch-get", "get values in batches in a transaction", []string{"txnkv.begin"}, testTxnKV{}.checkBatchGet)
+
+func (t testTxnKV) checkBatchGet(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	mustLoad(ctx, cluster, mocktikv.StorageTxn, "k1", "v1")
+	txn := t.mustBegin(ctx, client)
+	values, err := txn.BatchGet(bss("k1", "k2"))
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	ctx.AssertEQ(string(values["k1"]), "v1")
+	ctx.Assert(len(values["k2"]) == 0, "expect empty value of k2")
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterFeature("txnkv.iter", "iterate key-value pairs in a transaction", []string{"txnkv.begin"}, testTxnKV{}.checkIter)
+
+func (t testTxnKV) checkIter(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	mustLoad(ctx, cluster, mocktikv.StorageTxn, "k1", "v1")
+	txn := t.mustBegin(ctx, client)
+	iter, err := txn.Iter([]byte("k"), nil)
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	defer iter.Close()
+	valid, err := iter.Valid()
+	ctx.AssertNil(err)
+	ctx.Assert(valid, "expect iterator positioned at k1")
+	key, err := iter.Key()
+	ctx.AssertNil(err)
+	ctx.AssertEQ(string(key), "k1")
+	ctx.AssertNil(iter.Next())
+	valid, err = iter.Valid()
+	ctx.AssertNil(err)
+	ctx.Assert(!valid, "expect iterator is invalid after the last key")
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterStory("basic txnkv client", "txnkv.new", "txnkv.close", "txnkv.get-ts", "txnkv.begin", "txnkv.get", "txnkv.batch-get", "txnkv.set", "txnkv.delete", "txnkv.commit", "txnkv.rollback")
+
+var _ = validator.RegisterTest("simple txnkv get/set/delete", []string{"txnkv.get", "txnkv.delete", "txnkv.commit", "txnkv.rollback"}, testTxnKV{}.testSimple)
+
+func (t testTxnKV) testSimple(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	txn := t.mustBegin(ctx, client)
+	t.mustNotExist(ctx, txn, "key")
+	t.mustSet(ctx, txn, "key", "value")
+	t.mustGet(ctx, txn, "key", "value")
+	t.mustCommit(ctx, txn)
+
+	txn = t.mustBegin(ctx, client)
+	t.mustGet(ctx, txn, "key", "value")
+	t.mustDelete(ctx, txn, "key")
+	t.mustNotExist(ctx, txn, "key")
+	t.mustRollback(ctx, txn)
+
+	txn = t.mustBegin(ctx, client)
+	t.mustGet(ctx, txn, "key", "value")
+	t.mustDelete(ctx, txn, "key")
+	t.mustCommit(ctx, txn)
+
+	txn = t.mustBegin(ctx, client)
+	t.mustNotExist(ctx, txn, "key")
+}
+
+func (t testTxnKV) mustBegin(ctx validator.ExecContext, client stub.TxnKV) stub.Transaction {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	txn, err := client.Begin()
+	ctx.AssertNil(err)
+	return txn
+}
+
+func (t testTxnKV) mustGetTS(ctx validator.ExecContext, client stub.TxnKV) uint64 {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	ts, err := client.GetTS()
+	ctx.AssertNil(err)
+	return ts
+}
+
+func (t testTxnKV) mustNotExist(ctx validator.ExecContext, txn stub.Transaction, key string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	v, err := txn.Get([]byte(key))
+	ctx.AssertNil(err)
+	ctx.AssertEQ(len(v), 0)
+}
+
+func (t testTxnKV) mustGet(ctx validator.ExecContext, txn stub.Transaction, key, value string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	val, err := txn.Get([]byte(key))
+	ctx.AssertNil(err)
+	ctx.AssertEQ(string(val), value)
+}
+
+// mustBatchGet checks values of keys, an empty value means the key does not
+// exist.
+func (t testTxnKV) mustBatchGet(ctx validator.ExecContext, txn stub.Transaction, keys, values []string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	m, err := txn.BatchGet(bss(keys...))
+	ctx.AssertNil(err)
+	for i, k := range keys {
+		ctx.AssertEQ(string(m[k]), values[i])
+	}
+}
+
+// mustIter iterates keys in range [start, upperBound) and checks key-value
+// pairs. An empty upperBound means no bound.
+func (t testTxnKV) mustIter(ctx validator.ExecContext, txn stub.Transaction, start, upperBound string, expect ...string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	var upper []byte
+	if upperBound != "" {
+		upper = []byte(upperBound)
+	}
+	iter, err := txn.Iter([]byte(start), upper)
+	ctx.AssertNil(err)
+	defer iter.Close()
+	t.mustIterKVs(ctx, iter, expect...)
+}
+
+// mustIterKVs consumes the iterator and checks key-value pairs.
+func (t testTxnKV) mustIterKVs(ctx validator.ExecContext, iter stub.Iterator, expect ...string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	var kvs []string
+	for {
+		valid, err := iter.Valid()
+		ctx.AssertNil(err)
+		if !valid {
+			break
+		}
+		k, err := iter.Key()
+		ctx.AssertNil(err)
+		v, err := iter.Value()
+		ctx.AssertNil(err)
+		kvs = append(kvs, string(k), string(v))
+		ctx.AssertNil(iter.Next())
+	}
+	ctx.AssertEQ(len(kvs), len(expect))
+	for i := range kvs {
+		ctx.AssertEQ(kvs[i], expect[i])
+	}
+}
+
+func (t testTxnKV) mustSet(ctx validator.ExecContext, txn stub.Transaction, key, value string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	err := txn.Set([]byte(key), []byte(value))
+	ctx.AssertNil(err)
+}
+
+func (t testTxnKV) mustDelete(ctx validator.ExecContext, txn stub.Transaction, key string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	err := txn.Delete([]byte(key))
+	ctx.AssertNil(err)
+}
+
+func (t testTxnKV) mustCommit(ctx validator.ExecContext, txn stub.Transaction) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	err := txn.Commit()
+	ctx.AssertNil(err)
+}
+
+func (t testTxnKV) mustRollback(ctx validator.ExecContext, txn stub.Transaction) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	err := txn.Rollback()
+	ctx.AssertNil(err)
+}
@@ -0,0 +1,202 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"time"
+
+	"github.com/tikv/client-validator/mocktikv"
+	"github.com/tikv/client-validator/stub"
+	"github.com/tikv/client-validator/validator"
+)
+
+var _ = validator.RegisterFeature("txnkv.async-commit", "commit a transaction with async commit protocol", []string{"txnkv.get", "txnkv.commit"}, testTxnKV{}.checkAsyncCommit)
+
+func (t testTxnKV) checkAsyncCommit(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	txn, err := client.BeginWithOptions(stub.TxnOptions{AsyncCommit: true})
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	t.mustSet(ctx, txn, "k", "v")
+	if err = txn.Commit(); err != nil {
+		return errToFeatureStatus(err)
+	}
+	t.mustGet(ctx, t.mustBegin(ctx, client), "k", "v")
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterFeature("txnkv.1pc", "commit a transaction with one-phase commit", []string{"txnkv.get", "txnkv.commit"}, testTxnKV{}.checkOnePC)
+
+func (t testTxnKV) checkOnePC(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	txn, err := client.BeginWithOptions(stub.TxnOptions{OnePC: true})
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	t.mustSet(ctx, txn, "k", "v")
+	if err = txn.Commit(); err != nil {
+		return errToFeatureStatus(err)
+	}
+	t.mustGet(ctx, t.mustBegin(ctx, client), "k", "v")
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterStory("transaction commit protocols", "txnkv.async-commit", "txnkv.1pc")
+
+var _ = validator.RegisterTest("2pc transaction is rolled forward after primary is committed", []string{"txnkv.get", "txnkv.commit"}, testTxnKV{}.testCrashAfterCommitPrimary)
+
+func (t testTxnKV) testCrashAfterCommitPrimary(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys := []string{"k1", "k2", "k3", "k4"}
+	mustSplit(ctx, cluster, "", "k3")
+	mustEnableFailpoint(ctx, cluster, mocktikv.FailpointBeforeCommitSecondaries)
+	txn := t.mustBegin(ctx, client)
+	for _, k := range keys {
+		t.mustSet(ctx, txn, k, "v-"+k)
+	}
+	// The transaction is committed once the primary key is committed.
+	t.mustCommit(ctx, txn)
+	mustDisableFailpoint(ctx, cluster, mocktikv.FailpointBeforeCommitSecondaries)
+
+	t.mustResolvedValues(ctx, cluster, keys, "v-")
+}
+
+var _ = validator.RegisterTest("2pc transaction is rolled back after crash before commit", []string{"txnkv.get", "txnkv.commit"}, testTxnKV{}.testCrashBeforeCommitPrimary)
+
+func (t testTxnKV) testCrashBeforeCommitPrimary(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys := []string{"k1", "k2", "k3", "k4"}
+	mustSplit(ctx, cluster, "", "k3")
+	mustEnableFailpoint(ctx, cluster, mocktikv.FailpointBeforeCommitPrimary)
+	txn := t.mustBegin(ctx, client)
+	for _, k := range keys {
+		t.mustSet(ctx, txn, k, "v-"+k)
+	}
+	ctx.AssertNotNil(txn.Commit(), "commit should fail if primary key is not committed")
+	mustDisableFailpoint(ctx, cluster, mocktikv.FailpointBeforeCommitPrimary)
+
+	// Locks are left behind, readers roll them back after they expire.
+	mustAdvanceClock(ctx, cluster, time.Minute)
+	t.mustResolvedValues(ctx, cluster, keys, "")
+}
+
+var _ = validator.RegisterTest("async commit transaction is committed after crash before commit", []string{"txnkv.async-commit"}, testTxnKV{}.testAsyncCommitCrash)
+
+func (t testTxnKV) testAsyncCommitCrash(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys := []string{"k1", "k2", "k3", "k4"}
+	mustSplit(ctx, cluster, "", "k3")
+	mustEnableFailpoint(ctx, cluster, mocktikv.FailpointBeforeCommitPrimary)
+	txn, err := client.BeginWithOptions(stub.TxnOptions{AsyncCommit: true})
+	ctx.AssertNil(err)
+	for _, k := range keys {
+		t.mustSet(ctx, txn, k, "v-"+k)
+	}
+	// With async commit, the transaction is committed once all keys are
+	// prewritten. The client may or may not report the failure of the commit
+	// phase, readers should see the committed values anyway.
+	_ = txn.Commit()
+	mustDisableFailpoint(ctx, cluster, mocktikv.FailpointBeforeCommitPrimary)
+
+	// Locks are left behind, readers roll them forward after they expire.
+	mustAdvanceClock(ctx, cluster, time.Minute)
+	t.mustResolvedValues(ctx, cluster, keys, "v-")
+}
+
+var _ = validator.RegisterTest("async commit transaction is committed after crash before commit of secondaries", []string{"txnkv.async-commit"}, testTxnKV{}.testAsyncCommitCrashSecondaries)
+
+func (t testTxnKV) testAsyncCommitCrashSecondaries(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys := []string{"k1", "k2", "k3", "k4"}
+	mustSplit(ctx, cluster, "", "k3")
+	mustEnableFailpoint(ctx, cluster, mocktikv.FailpointBeforeCommitSecondaries)
+	txn, err := client.BeginWithOptions(stub.TxnOptions{AsyncCommit: true})
+	ctx.AssertNil(err)
+	for _, k := range keys {
+		t.mustSet(ctx, txn, k, "v-"+k)
+	}
+	t.mustCommit(ctx, txn)
+	mustDisableFailpoint(ctx, cluster, mocktikv.FailpointBeforeCommitSecondaries)
+
+	t.mustResolvedValues(ctx, cluster, keys, "v-")
+}
+
+var _ = validator.RegisterTest("1pc transaction does not need commit phase", []string{"txnkv.1pc"}, testTxnKV{}.testOnePC)
+
+func (t testTxnKV) testOnePC(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	// All keys are in one region, the transaction should be committed without
+	// sending commit requests.
+	keys := []string{"k1", "k2"}
+	mustEnableFailpoint(ctx, cluster, mocktikv.FailpointBeforeCommitPrimary)
+	txn, err := client.BeginWithOptions(stub.TxnOptions{OnePC: true})
+	ctx.AssertNil(err)
+	for _, k := range keys {
+		t.mustSet(ctx, txn, k, "v-"+k)
+	}
+	t.mustCommit(ctx, txn)
+	mustDisableFailpoint(ctx, cluster, mocktikv.FailpointBeforeCommitPrimary)
+	t.mustResolvedValues(ctx, cluster, keys, "v-")
+
+	// Keys are in different regions, the client should fall back to 2PC.
+	keys = []string{"k3", "k4"}
+	mustSplit(ctx, cluster, "k3", "k4")
+	txn, err = client.BeginWithOptions(stub.TxnOptions{OnePC: true})
+	ctx.AssertNil(err)
+	for _, k := range keys {
+		t.mustSet(ctx, txn, k, "v-"+k)
+	}
+	t.mustCommit(ctx, txn)
+	t.mustResolvedValues(ctx, cluster, keys, "v-")
+}
+
+// mustResolvedValues reads keys with a new client, which has to resolve locks
+// left by other clients. The value of each key should be prefix+key, or not
+// exist if prefix is empty.
+func (t testTxnKV) mustResolvedValues(ctx validator.ExecContext, cluster *mocktikv.Cluster, keys []string, prefix string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	client := t.newClientWithCluster(ctx, cluster)
+	defer client.Close()
+	txn := t.mustBegin(ctx, client)
+	for _, k := range keys {
+		if prefix == "" {
+			t.mustNotExist(ctx, txn, k)
+		} else {
+			t.mustGet(ctx, txn, k, prefix+k)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"fmt"
+
+	"github.com/tikv/client-validator/mocktikv"
+	"github.com/tikv/client-validator/stub"
+	"github.com/tikv/client-validator/validator"
+)
+
+var _ = validator.RegisterFeature("txnkv.iter-reverse", "iterate key-value pairs in reverse order in a transaction", []string{"txnkv.begin"}, testTxnKV{}.checkIterReverse)
+
+func (t testTxnKV) checkIterReverse(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	mustLoad(ctx, cluster, mocktikv.StorageTxn, "k1", "v1", "k2", "v2")
+	txn := t.mustBegin(ctx, client)
+	iter, err := txn.IterReverse([]byte("k2"))
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	defer iter.Close()
+	t.mustIterKVs(ctx, iter, "k1", "v1")
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterStory("txnkv iterator", "txnkv.iter", "txnkv.iter-reverse")
+
+var _ = validator.RegisterTest("iterate empty range", []string{"txnkv.iter", "txnkv.iter-reverse"}, testTxnKV{}.testIterEmpty)
+
+func (t testTxnKV) testIterEmpty(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	txn := t.mustBegin(ctx, client)
+	t.mustIter(ctx, txn, "", "")
+	t.mustIterReverse(ctx, txn, "")
+	t.mustIterReverse(ctx, txn, "z")
+
+	mustLoad(ctx, cluster, mocktikv.StorageTxn, "a", "va", "z", "vz")
+	txn = t.mustBegin(ctx, client)
+	t.mustIter(ctx, txn, "k", "l")
+	t.mustIter(ctx, txn, "k", "k")
+	t.mustIter(ctx, txn, "z\x00", "")
+	t.mustIterReverse(ctx, txn, "a")
+}
+
+var _ = validator.RegisterTest("iterator bounds", []string{"txnkv.iter", "txnkv.iter-reverse"}, testTxnKV{}.testIterBounds)
+
+func (t testTxnKV) testIterBounds(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	mustLoad(ctx, cluster, mocktikv.StorageTxn, "k1", "v1", "k2", "v2", "k3", "v3")
+	txn := t.mustBegin(ctx, client)
+
+	// Start key is inclusive and upper bound is exclusive.
+	t.mustIter(ctx, txn, "k1", "k3", "k1", "v1", "k2", "v2")
+	t.mustIter(ctx, txn, "k2", "k3", "k2", "v2")
+	t.mustIter(ctx, txn, "k2", "k2")
+	t.mustIter(ctx, txn, "k1\x00", "k3\x00", "k2", "v2", "k3", "v3")
+	t.mustIter(ctx, txn, "", "", "k1", "v1", "k2", "v2", "k3", "v3")
+
+	// Reverse iteration starts from the first key which is less than the key.
+	t.mustIterReverse(ctx, txn, "k3", "k2", "v2", "k1", "v1")
+	t.mustIterReverse(ctx, txn, "k25", "k2", "v2", "k1", "v1")
+	t.mustIterReverse(ctx, txn, "k3\x00", "k3", "v3", "k2", "v2", "k1", "v1")
+	t.mustIterReverse(ctx, txn, "k1")
+	t.mustIterReverse(ctx, txn, "", "k3", "v3", "k2", "v2", "k1", "v1")
+}
+
+var _ = validator.RegisterTest("iterate across regions", []string{"txnkv.iter", "txnkv.iter-reverse"}, testTxnKV{}.testIterRegions)
+
+func (t testTxnKV) testIterRegions(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	var kvs []string
+	for i := 0; i < 20; i++ {
+		kvs = append(kvs, fmt.Sprintf("k%02d", i), fmt.Sprintf("v%02d", i))
+	}
+	reversed := make([]string, 0, len(kvs))
+	for i := len(kvs) - 2; i >= 0; i -= 2 {
+		reversed = append(reversed, kvs[i], kvs[i+1])
+	}
+	mustLoad(ctx, cluster, mocktikv.StorageTxn, kvs...)
+
+	check := func() {
+		ctx.AddCallerDepth(1)
+		defer ctx.AddCallerDepth(-1)
+		txn := t.mustBegin(ctx, client)
+		t.mustIter(ctx, txn, "", "", kvs...)
+		t.mustIter(ctx, txn, "k05", "k15", kvs[10:30]...)
+		t.mustIterReverse(ctx, txn, "", reversed...)
+		t.mustIterReverse(ctx, txn, "k15", reversed[10:]...)
+	}
+	check()
+	mustSplit(ctx, cluster, "", "k10")
+	check()
+	mustSplit(ctx, cluster, "", "k05")
+	mustSplit(ctx, cluster, "k10", "k15")
+	check()
+
+	// Split regions while iterators are positioned in the middle.
+	txn := t.mustBegin(ctx, client)
+	iter, err := txn.Iter(nil, nil)
+	ctx.AssertNil(err)
+	defer iter.Close()
+	reverseIter, err := txn.IterReverse(nil)
+	ctx.AssertNil(err)
+	defer reverseIter.Close()
+	for i := 0; i < 3; i++ {
+		ctx.AssertNil(iter.Next())
+		ctx.AssertNil(reverseIter.Next())
+	}
+	mustSplit(ctx, cluster, "k15", "k17")
+	mustSplit(ctx, cluster, "", "k02")
+	t.mustIterKVs(ctx, iter, kvs[6:]...)
+	t.mustIterKVs(ctx, reverseIter, reversed[6:]...)
+}
+
+var _ = validator.RegisterTest("iterator merges uncommitted changes with snapshot", []string{"txnkv.set", "txnkv.delete", "txnkv.iter", "txnkv.iter-reverse"}, testTxnKV{}.testIterMembuffer)
+
+func (t testTxnKV) testIterMembuffer(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	mustLoad(ctx, cluster, mocktikv.StorageTxn, "k1", "v1", "k2", "v2", "k3", "v3", "k5", "v5")
+	txn := t.mustBegin(ctx, client)
+	t.mustSet(ctx, txn, "k0", "new0")
+	t.mustSet(ctx, txn, "k2", "new2")
+	t.mustDelete(ctx, txn, "k3")
+	t.mustSet(ctx, txn, "k4", "new4")
+	t.mustDelete(ctx, txn, "k6")
+	t.mustSet(ctx, txn, "k7", "new7")
+	t.mustDelete(ctx, txn, "k7")
+
+	t.mustIter(ctx, txn, "", "", "k0", "new0", "k1", "v1", "k2", "new2", "k4", "new4", "k5", "v5")
+	t.mustIter(ctx, txn, "k2", "k5", "k2", "new2", "k4", "new4")
+	t.mustIterReverse(ctx, txn, "", "k5", "v5", "k4", "new4", "k2", "new2", "k1", "v1", "k0", "new0")
+	t.mustIterReverse(ctx, txn, "k4", "k2", "new2", "k1", "v1", "k0", "new0")
+
+	// Uncommitted changes are invisible to other transactions.
+	t.mustIter(ctx, t.mustBegin(ctx, client), "", "", "k1", "v1", "k2", "v2", "k3", "v3", "k5", "v5")
+}
+
+var _ = validator.RegisterTest("use iterator after close or transaction end", []string{"txnkv.commit", "txnkv.rollback", "txnkv.iter"}, testTxnKV{}.testIterClosed)
+
+func (t testTxnKV) testIterClosed(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	mustLoad(ctx, cluster, mocktikv.StorageTxn, "k1", "v1", "k2", "v2")
+
+	txn := t.mustBegin(ctx, client)
+	iter, err := txn.Iter(nil, nil)
+	ctx.AssertNil(err)
+	ctx.AssertNil(iter.Close())
+	t.mustIterUnusable(ctx, iter)
+
+	txn = t.mustBegin(ctx, client)
+	iter, err = txn.Iter(nil, nil)
+	ctx.AssertNil(err)
+	t.mustSet(ctx, txn, "k3", "v3")
+	t.mustCommit(ctx, txn)
+	t.mustIterUnusable(ctx, iter)
+	_, err = txn.Iter(nil, nil)
+	ctx.AssertNotNil(err, "iter should fail after the transaction is committed")
+
+	txn = t.mustBegin(ctx, client)
+	iter, err = txn.IterReverse(nil)
+	ctx.AssertNil(err)
+	t.mustRollback(ctx, txn)
+	t.mustIterUnusable(ctx, iter)
+	_, err = txn.IterReverse(nil)
+	ctx.AssertNotNil(err, "iter should fail after the transaction is rolled back")
+}
+
+// mustIterUnusable checks that moving the iterator returns an error.
+func (t testTxnKV) mustIterUnusable(ctx validator.ExecContext, iter stub.Iterator) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	ctx.AssertNotNil(iter.Next(), "next should fail on an unusable iterator")
+	valid, err := iter.Valid()
+	ctx.Assert(err != nil || !valid, "iterator should be invalid")
+}
+
+// mustIterReverse iterates keys less than k in reverse order and checks
+// key-value pairs. An empty k means no bound.
+func (t testTxnKV) mustIterReverse(ctx validator.ExecContext, txn stub.Transaction, k string, expect ...string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	var key []byte
+	if k != "" {
+		key = []byte(k)
+	}
+	iter, err := txn.IterReverse(key)
+	ctx.AssertNil(err)
+	defer iter.Close()
+	t.mustIterKVs(ctx, iter, expect...)
+}
@@ -0,0 +1,227 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/tikv/client-validator/mocktikv"
+	"github.com/tikv/client-validator/stub"
+	"github.com/tikv/client-validator/validator"
+)
+
+var (
+	txnEntrySizeLimit = flag.Uint64("txn-entry-size-limit", 16*1024, "entry size limit (in bytes) of transactions in membuffer tests")
+	txnTotalSizeLimit = flag.Uint64("txn-total-size-limit", 256*1024, "total size limit (in bytes) of transactions in membuffer tests")
+)
+
+var _ = validator.RegisterFeature("txnkv.membuffer-size", "report count and size of entries in a transaction", []string{"txnkv.set"}, testTxnKV{}.checkMembufferSize)
+
+func (t testTxnKV) checkMembufferSize(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	txn := t.mustBegin(ctx, client)
+	t.mustSet(ctx, txn, "key", "value")
+	if _, err := txn.Len(); err != nil {
+		return errToFeatureStatus(err)
+	}
+	if _, err := txn.Size(); err != nil {
+		return errToFeatureStatus(err)
+	}
+	t.mustLenSize(ctx, txn, 1, 8)
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterFeature("txnkv.membuffer-limits", "limit size of entries in a transaction", []string{"txnkv.set"}, testTxnKV{}.checkMembufferLimits)
+
+func (t testTxnKV) checkMembufferLimits(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	txn, err := client.BeginWithOptions(stub.TxnOptions{EntrySizeLimit: 1024})
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	err = txn.Set([]byte("k"), make([]byte, 2048))
+	if err == nil {
+		ctx.Log("set succeeds with an entry of 2048 bytes, entry size limit is 1024")
+		return validator.FeatureFail
+	}
+	if !isEntryTooLargeErr(err) {
+		ctx.Log("expect entry too large error, got %v", err)
+		return errToFeatureStatus(err)
+	}
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterStory("transaction membuffer", "txnkv.membuffer-size", "txnkv.membuffer-limits")
+
+var _ = validator.RegisterTest("membuffer len and size accounting", []string{"txnkv.delete", "txnkv.rollback", "txnkv.membuffer-size"}, testTxnKV{}.testMembufferSize)
+
+// testMembufferSize checks Len and Size of a transaction. Every key which is
+// set or deleted is an entry, the size of an entry is the length of the key
+// plus the length of the latest value, a deleted key has an empty value.
+func (t testTxnKV) testMembufferSize(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	// Keys in the snapshot are not counted.
+	mustLoad(ctx, cluster, mocktikv.StorageTxn, "k000", "v", "k001", "v")
+	txn := t.mustBegin(ctx, client)
+	t.mustLenSize(ctx, txn, 0, 0)
+	t.mustGet(ctx, txn, "k000", "v")
+	t.mustLenSize(ctx, txn, 0, 0)
+
+	entries := make(map[string]string)
+	check := func() {
+		ctx.AddCallerDepth(1)
+		defer ctx.AddCallerDepth(-1)
+		var size int
+		for k, v := range entries {
+			size += len(k) + len(v)
+		}
+		t.mustLenSize(ctx, txn, len(entries), size)
+	}
+	r := ctx.Rand()
+	for i := 0; i < 500; i++ {
+		key := fmt.Sprintf("k%03d", r.Intn(100))
+		if r.Intn(4) == 0 {
+			t.mustDelete(ctx, txn, key)
+			entries[key] = ""
+		} else {
+			value := strings.Repeat("v", 1+r.Intn(64))
+			t.mustSet(ctx, txn, key, value)
+			entries[key] = value
+		}
+		if i%10 == 0 {
+			check()
+		}
+	}
+	check()
+
+	t.mustRollback(ctx, txn)
+	t.mustLenSize(ctx, t.mustBegin(ctx, client), 0, 0)
+}
+
+var _ = validator.RegisterTest("entry size limit", []string{"txnkv.commit", "txnkv.membuffer-size", "txnkv.membuffer-limits"}, testTxnKV{}.testEntrySizeLimit)
+
+func (t testTxnKV) testEntrySizeLimit(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	limit := int(*txnEntrySizeLimit)
+	txn := t.mustBeginWithLimits(ctx, client)
+	// An entry exactly at the limit is accepted.
+	t.mustSet(ctx, txn, "k1", strings.Repeat("v", limit-2))
+	t.mustLenSize(ctx, txn, 1, limit)
+
+	err := txn.Set([]byte("k2"), []byte(strings.Repeat("v", limit-1)))
+	ctx.Assert(err != nil && isEntryTooLargeErr(err), fmt.Sprintf("expect entry too large error, got %v", err))
+	err = txn.Set([]byte(strings.Repeat("k", limit+1)), []byte("v"))
+	ctx.Assert(err != nil && isEntryTooLargeErr(err), fmt.Sprintf("expect entry too large error, got %v", err))
+
+	// Rejected entries are not buffered, the transaction is still usable.
+	t.mustLenSize(ctx, txn, 1, limit)
+	t.mustNotExist(ctx, txn, "k2")
+	t.mustSet(ctx, txn, "k3", "v3")
+	t.mustCommit(ctx, txn)
+	mustDump(ctx, cluster, mocktikv.StorageTxn, "", "", "k1", strings.Repeat("v", limit-2), "k3", "v3")
+}
+
+var _ = validator.RegisterTest("transaction size limit", []string{"txnkv.commit", "txnkv.rollback", "txnkv.membuffer-size", "txnkv.membuffer-limits"}, testTxnKV{}.testTxnSizeLimit)
+
+func (t testTxnKV) testTxnSizeLimit(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	limit := int(*txnTotalSizeLimit)
+	entrySize := int(*txnEntrySizeLimit) / 2
+	if entrySize > limit/4 {
+		entrySize = limit / 4
+	}
+	// Keys are of the same width, so that all entries are of entrySize. There
+	// are at most limit+1 entries.
+	keyFormat := fmt.Sprintf("k%%0%dd", len(fmt.Sprint(limit+1)))
+	keyLen := len(fmt.Sprintf(keyFormat, 0))
+	ctx.Assert(entrySize > keyLen, fmt.Sprintf("size limits %d and %d are too small to build entries of %d bytes",
+		*txnEntrySizeLimit, *txnTotalSizeLimit, entrySize))
+	value := strings.Repeat("v", entrySize-keyLen)
+
+	// The client may reject the entry that exceeds the limit, or reject the
+	// transaction when it commits. Either way nothing should be written.
+	txn := t.mustBeginWithLimits(ctx, client)
+	var err error
+	for i := 0; i <= limit/entrySize && err == nil; i++ {
+		err = txn.Set([]byte(fmt.Sprintf(keyFormat, i)), []byte(value))
+	}
+	if err == nil {
+		err = txn.Commit()
+	} else {
+		size, sizeErr := txn.Size()
+		ctx.AssertNil(sizeErr)
+		ctx.Assert(size <= limit, fmt.Sprintf("membuffer size %v exceeds the limit %v", size, limit))
+		t.mustRollback(ctx, txn)
+	}
+	ctx.Assert(err != nil && isTxnTooLargeErr(err), fmt.Sprintf("expect transaction too large error, got %v", err))
+	mustDump(ctx, cluster, mocktikv.StorageTxn, "", "")
+
+	// A transaction within the limit is committed by the same client.
+	txn = t.mustBeginWithLimits(ctx, client)
+	for i := 0; i < limit/entrySize; i++ {
+		t.mustSet(ctx, txn, fmt.Sprintf(keyFormat, i), value)
+	}
+	t.mustLenSize(ctx, txn, limit/entrySize, limit/entrySize*entrySize)
+	t.mustCommit(ctx, txn)
+}
+
+func isEntryTooLargeErr(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "entry") && strings.Contains(msg, "too large")
+}
+
+func isTxnTooLargeErr(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return (strings.Contains(msg, "transaction") || strings.Contains(msg, "txn")) && strings.Contains(msg, "too large")
+}
+
+// mustBeginWithLimits begins a transaction with limits given by flags.
+func (t testTxnKV) mustBeginWithLimits(ctx validator.ExecContext, client stub.TxnKV) stub.Transaction {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	txn, err := client.BeginWithOptions(stub.TxnOptions{
+		EntrySizeLimit: *txnEntrySizeLimit,
+		TotalSizeLimit: *txnTotalSizeLimit,
+	})
+	ctx.AssertNil(err)
+	return txn
+}
+
+func (t testTxnKV) mustLenSize(ctx validator.ExecContext, txn stub.Transaction, length, size int) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	l, err := txn.Len()
+	ctx.AssertNil(err)
+	ctx.AssertEQ(l, length)
+	s, err := txn.Size()
+	ctx.AssertNil(err)
+	ctx.AssertEQ(s, size)
+}
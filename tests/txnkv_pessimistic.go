@@ -0,0 +1,216 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tikv/client-validator/stub"
+	"github.com/tikv/client-validator/validator"
+)
+
+var _ = validator.RegisterFeature("txnkv.begin-pessimistic", "begin a pessimistic transaction", []string{"txnkv.begin"}, testTxnKV{}.checkBeginPessimistic)
+
+func (t testTxnKV) checkBeginPessimistic(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	_, err := client.BeginPessimistic()
+	return errToFeatureStatus(err)
+}
+
+var _ = validator.RegisterFeature("txnkv.pessimistic-lock", "lock keys in a pessimistic transaction", []string{"txnkv.begin-pessimistic", "txnkv.commit"}, testTxnKV{}.checkPessimisticLock)
+
+func (t testTxnKV) checkPessimisticLock(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	txn := t.mustBeginPessimistic(ctx, client)
+	err := txn.LockKeys([]byte("k"))
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	t.mustSet(ctx, txn, "k", "v")
+	t.mustCommit(ctx, txn)
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterStory("pessimistic transaction", "txnkv.begin-pessimistic", "txnkv.pessimistic-lock")
+
+var _ = validator.RegisterTest("pessimistic lock waits for lock release", []string{"txnkv.get", "txnkv.pessimistic-lock"}, testTxnKV{}.testLockWait)
+
+func (t testTxnKV) testLockWait(ctx validator.ExecContext) {
+	cluster, client1 := t.newClient(ctx)
+	defer cluster.Close()
+	defer client1.Close()
+	client2 := t.newClientWithCluster(ctx, cluster)
+	defer client2.Close()
+
+	txn1 := t.mustBeginPessimistic(ctx, client1)
+	t.mustLockKeys(ctx, txn1, "k")
+	t.mustSet(ctx, txn1, "k", "v1")
+
+	txn2 := t.mustBeginPessimistic(ctx, client2)
+	txn2.SetLockWaitTimeout(5 * time.Second)
+	done := make(chan error, 1)
+	go func() { done <- txn2.LockKeys([]byte("k")) }()
+	select {
+	case err := <-done:
+		ctx.Fail(fmt.Sprintf("lock keys should wait for the lock, got %v", err))
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	t.mustCommit(ctx, txn1)
+	select {
+	case err := <-done:
+		ctx.AssertNil(err)
+	case <-time.After(5 * time.Second):
+		ctx.Fail("lock is not acquired after the lock is released")
+	}
+	t.mustSet(ctx, txn2, "k", "v2")
+	t.mustCommit(ctx, txn2)
+
+	t.mustGet(ctx, t.mustBegin(ctx, client1), "k", "v2")
+}
+
+var _ = validator.RegisterTest("pessimistic lock wait timeout", []string{"txnkv.rollback", "txnkv.pessimistic-lock"}, testTxnKV{}.testLockWaitTimeout)
+
+func (t testTxnKV) testLockWaitTimeout(ctx validator.ExecContext) {
+	cluster, client1 := t.newClient(ctx)
+	defer cluster.Close()
+	defer client1.Close()
+	client2 := t.newClientWithCluster(ctx, cluster)
+	defer client2.Close()
+
+	txn1 := t.mustBeginPessimistic(ctx, client1)
+	t.mustLockKeys(ctx, txn1, "k")
+
+	txn2 := t.mustBeginPessimistic(ctx, client2)
+	txn2.SetLockWaitTimeout(2 * time.Second)
+	start := time.Now()
+	err := txn2.LockKeys([]byte("k"))
+	ctx.AssertNotNil(err, "lock keys should fail when lock wait timeout")
+	ctx.Assert(time.Since(start) >= 1500*time.Millisecond, fmt.Sprintf("lock keys returns too early: %v", time.Since(start)))
+
+	txn2.SetLockWaitTimeout(-1)
+	start = time.Now()
+	err = txn2.LockKeys([]byte("k"))
+	ctx.AssertNotNil(err, "lock keys should fail immediately without waiting")
+	ctx.Assert(time.Since(start) < time.Second, fmt.Sprintf("lock keys without waiting takes too long: %v", time.Since(start)))
+
+	// Lock timeout does not abort the transaction.
+	t.mustRollback(ctx, txn1)
+	t.mustLockKeys(ctx, txn2, "k")
+	t.mustSet(ctx, txn2, "k", "v")
+	t.mustCommit(ctx, txn2)
+}
+
+var _ = validator.RegisterTest("deadlock between pessimistic transactions", []string{"txnkv.rollback", "txnkv.pessimistic-lock"}, testTxnKV{}.testDeadlock)
+
+func (t testTxnKV) testDeadlock(ctx validator.ExecContext) {
+	cluster, client1 := t.newClient(ctx)
+	defer cluster.Close()
+	defer client1.Close()
+	client2 := t.newClientWithCluster(ctx, cluster)
+	defer client2.Close()
+
+	txns := []stub.Transaction{t.mustBeginPessimistic(ctx, client1), t.mustBeginPessimistic(ctx, client2)}
+	t.mustLockKeys(ctx, txns[0], "a")
+	t.mustLockKeys(ctx, txns[1], "b")
+
+	type result struct {
+		i   int
+		err error
+	}
+	results := make(chan result, 2)
+	txns[0].SetLockWaitTimeout(5 * time.Second)
+	go func() { results <- result{0, txns[0].LockKeys([]byte("b"))} }()
+	time.Sleep(200 * time.Millisecond)
+	txns[1].SetLockWaitTimeout(5 * time.Second)
+	go func() { results <- result{1, txns[1].LockKeys([]byte("a"))} }()
+
+	var first result
+	select {
+	case first = <-results:
+	case <-time.After(4 * time.Second):
+		ctx.Fail("deadlock is not detected")
+	}
+	ctx.AssertNotNil(first.err, "one of the transactions should fail for deadlock")
+	ctx.Assert(isDeadlockErr(first.err), fmt.Sprintf("expect deadlock error, got %v", first.err))
+
+	t.mustRollback(ctx, txns[first.i])
+	select {
+	case second := <-results:
+		ctx.AssertNil(second.err)
+		t.mustCommit(ctx, txns[second.i])
+	case <-time.After(5 * time.Second):
+		ctx.Fail("lock is not acquired after the deadlock victim is rolled back")
+	}
+}
+
+var _ = validator.RegisterTest("pessimistic lock commits over write conflict failing optimistic transaction", []string{"txnkv.get", "txnkv.pessimistic-lock"}, testTxnKV{}.testPessimisticConflict)
+
+// testPessimisticConflict writes a key which is updated after the transaction
+// starts, the optimistic transaction fails to commit while the pessimistic one
+// commits after locking the key.
+func (t testTxnKV) testPessimisticConflict(ctx validator.ExecContext) {
+	cluster, client1 := t.newClient(ctx)
+	defer cluster.Close()
+	defer client1.Close()
+	client2 := t.newClientWithCluster(ctx, cluster)
+	defer client2.Close()
+
+	// Optimistic transaction fails to commit when the key is updated after it starts.
+	txn1 := t.mustBegin(ctx, client1)
+	txn2 := t.mustBegin(ctx, client2)
+	t.mustSet(ctx, txn2, "k", "v2")
+	t.mustCommit(ctx, txn2)
+	t.mustSet(ctx, txn1, "k", "v1")
+	ctx.AssertNotNil(txn1.Commit(), "optimistic transaction should fail for write conflict")
+	t.mustGet(ctx, t.mustBegin(ctx, client1), "k", "v2")
+
+	// Pessimistic transaction locks the key with a newer for-update-ts, and
+	// commits successfully.
+	txn1 = t.mustBeginPessimistic(ctx, client1)
+	txn2 = t.mustBegin(ctx, client2)
+	t.mustSet(ctx, txn2, "k", "v3")
+	t.mustCommit(ctx, txn2)
+	t.mustLockKeys(ctx, txn1, "k")
+	t.mustSet(ctx, txn1, "k", "v4")
+	t.mustCommit(ctx, txn1)
+	t.mustGet(ctx, t.mustBegin(ctx, client1), "k", "v4")
+}
+
+func isDeadlockErr(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "deadlock")
+}
+
+func (t testTxnKV) mustBeginPessimistic(ctx validator.ExecContext, client stub.TxnKV) stub.Transaction {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	txn, err := client.BeginPessimistic()
+	ctx.AssertNil(err)
+	return txn
+}
+
+func (t testTxnKV) mustLockKeys(ctx validator.ExecContext, txn stub.Transaction, keys ...string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	err := txn.LockKeys(bss(keys...)...)
+	ctx.AssertNil(err)
+}
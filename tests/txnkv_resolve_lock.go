@@ -0,0 +1,182 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tikv/client-validator/mocktikv"
+	"github.com/tikv/client-validator/stub"
+	"github.com/tikv/client-validator/validator"
+)
+
+// lockTTLLong is the TTL (in milliseconds) of locks which should not expire
+// during a test.
+const lockTTLLong = 10 * 60 * 1000
+
+var _ = validator.RegisterFeature("txnkv.resolve-lock", "resolve locks left by dead transactions", []string{"txnkv.get", "txnkv.get-ts"}, testTxnKV{}.checkResolveLock)
+
+func (t testTxnKV) checkResolveLock(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	mustPutLock(ctx, cluster, mocktikv.MockLock{Key: []byte("k"), Value: []byte("v"), Primary: []byte("k"), StartTS: t.mustGetTS(ctx, client)})
+	mustAdvanceClock(ctx, cluster, time.Minute)
+	val, err := t.mustBegin(ctx, client).Get([]byte("k"))
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	ctx.Assert(len(val) == 0, "expect expired lock is rolled back")
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterStory("resolve locks left by dead transactions", "txnkv.resolve-lock")
+
+var _ = validator.RegisterTest("expired locks are rolled back", []string{"txnkv.batch-get", "txnkv.iter", "txnkv.commit", "txnkv.resolve-lock"}, testTxnKV{}.testResolveExpiredLock)
+
+func (t testTxnKV) testResolveExpiredLock(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys := []string{"k1", "k2", "k3", "k4"}
+	t.mustSetKeys(ctx, client, keys, "v0-")
+	mustSplit(ctx, cluster, "", "k3")
+	t.mustPlantTxnLocks(ctx, cluster, t.mustGetTS(ctx, client), 0, 100, keys, "v1-")
+	mustAdvanceClock(ctx, cluster, time.Minute)
+
+	t.mustGet(ctx, t.mustBegin(ctx, client), "k1", "v0-k1")
+	t.mustBatchGet(ctx, t.mustBegin(ctx, client), keys, []string{"v0-k1", "v0-k2", "v0-k3", "v0-k4"})
+	t.mustIter(ctx, t.mustBegin(ctx, client), "k", "", "k1", "v0-k1", "k2", "v0-k2", "k3", "v0-k3", "k4", "v0-k4")
+
+	// Writing keys with expired locks should resolve them instead of failing.
+	t.mustPlantTxnLocks(ctx, cluster, t.mustGetTS(ctx, client), 0, 100, keys, "v1-")
+	mustAdvanceClock(ctx, cluster, time.Minute)
+	t.mustSetKeys(ctx, client, keys, "v2-")
+	t.mustBatchGet(ctx, t.mustBegin(ctx, client), keys, []string{"v2-k1", "v2-k2", "v2-k3", "v2-k4"})
+}
+
+var _ = validator.RegisterTest("locks whose primary is committed are rolled forward", []string{"txnkv.batch-get", "txnkv.iter", "txnkv.commit", "txnkv.resolve-lock"}, testTxnKV{}.testResolveCommittedLock)
+
+func (t testTxnKV) testResolveCommittedLock(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys := []string{"k1", "k2", "k3", "k4"}
+	t.mustSetKeys(ctx, client, keys, "v0-")
+	mustSplit(ctx, cluster, "", "k3")
+
+	// Locks of secondary keys are not expired, but they should be resolved
+	// without waiting since the primary key is committed.
+	startTS := t.mustGetTS(ctx, client)
+	t.mustPlantTxnLocks(ctx, cluster, startTS, t.mustGetTS(ctx, client), lockTTLLong, keys, "v1-")
+	t.mustGet(ctx, t.mustBegin(ctx, client), "k2", "v1-k2")
+	t.mustBatchGet(ctx, t.mustBegin(ctx, client), keys, []string{"v1-k1", "v1-k2", "v1-k3", "v1-k4"})
+	t.mustIter(ctx, t.mustBegin(ctx, client), "k", "", "k1", "v1-k1", "k2", "v1-k2", "k3", "v1-k3", "k4", "v1-k4")
+
+	startTS = t.mustGetTS(ctx, client)
+	t.mustPlantTxnLocks(ctx, cluster, startTS, t.mustGetTS(ctx, client), lockTTLLong, keys, "v2-")
+	t.mustSetKeys(ctx, client, keys, "v3-")
+	t.mustBatchGet(ctx, t.mustBegin(ctx, client), keys, []string{"v3-k1", "v3-k2", "v3-k3", "v3-k4"})
+}
+
+var _ = validator.RegisterTest("unexpired lock blocks readers until it expires", []string{"txnkv.commit", "txnkv.resolve-lock"}, testTxnKV{}.testResolveUnexpiredLock)
+
+func (t testTxnKV) testResolveUnexpiredLock(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	t.mustSetKeys(ctx, client, []string{"k"}, "v0-")
+	mustPutLock(ctx, cluster, mocktikv.MockLock{Key: []byte("k"), Value: []byte("v1-k"), Primary: []byte("k"), StartTS: t.mustGetTS(ctx, client), TTL: 3000})
+
+	txn := t.mustBegin(ctx, client)
+	type result struct {
+		val []byte
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		val, err := txn.Get([]byte("k"))
+		done <- result{val, err}
+	}()
+	select {
+	case r := <-done:
+		ctx.Fail(fmt.Sprintf("read should be blocked by the lock, got %q, %v", r.val, r.err))
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	mustAdvanceClock(ctx, cluster, time.Minute)
+	select {
+	case r := <-done:
+		ctx.AssertNil(r.err)
+		ctx.AssertEQ(string(r.val), "v0-k")
+	case <-time.After(5 * time.Second):
+		ctx.Fail("read is still blocked after the lock is expired")
+	}
+}
+
+var _ = validator.RegisterTest("lock newer than the reader does not block it", []string{"txnkv.batch-get", "txnkv.iter", "txnkv.commit", "txnkv.resolve-lock"}, testTxnKV{}.testLockNewerThanReader)
+
+func (t testTxnKV) testLockNewerThanReader(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	keys := []string{"k1", "k2"}
+	t.mustSetKeys(ctx, client, keys, "v0-")
+	txn := t.mustBegin(ctx, client)
+	t.mustPlantTxnLocks(ctx, cluster, t.mustGetTS(ctx, client), 0, lockTTLLong, keys, "v1-")
+
+	start := time.Now()
+	t.mustGet(ctx, txn, "k1", "v0-k1")
+	t.mustBatchGet(ctx, txn, keys, []string{"v0-k1", "v0-k2"})
+	t.mustIter(ctx, txn, "k", "", "k1", "v0-k1", "k2", "v0-k2")
+	ctx.Assert(time.Since(start) < time.Second, fmt.Sprintf("reads are blocked by newer locks for %v", time.Since(start)))
+}
+
+// mustSetKeys sets each key to prefix+key in a transaction.
+func (t testTxnKV) mustSetKeys(ctx validator.ExecContext, client stub.TxnKV, keys []string, prefix string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	txn := t.mustBegin(ctx, client)
+	for _, k := range keys {
+		t.mustSet(ctx, txn, k, prefix+k)
+	}
+	t.mustCommit(ctx, txn)
+}
+
+// mustPlantTxnLocks plants locks of a transaction which sets each key to
+// prefix+key. The first key is the primary key, it is committed if commitTS
+// is not zero.
+func (t testTxnKV) mustPlantTxnLocks(ctx validator.ExecContext, cluster *mocktikv.Cluster, startTS, commitTS, ttl uint64, keys []string, prefix string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	for i, k := range keys {
+		lock := mocktikv.MockLock{
+			Key:     []byte(k),
+			Value:   []byte(prefix + k),
+			Primary: []byte(keys[0]),
+			StartTS: startTS,
+			TTL:     ttl,
+		}
+		if i == 0 {
+			lock.CommitTS = commitTS
+		}
+		mustPutLock(ctx, cluster, lock)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tikv/client-validator/stub"
+	"github.com/tikv/client-validator/validator"
+)
+
+var _ = validator.RegisterFeature("txnkv.snapshot-read", "read at a specified timestamp", []string{"txnkv.get", "txnkv.get-ts"}, testTxnKV{}.checkSnapshotRead)
+
+func (t testTxnKV) checkSnapshotRead(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	ts := t.mustGetTS(ctx, client)
+	mustLoadVersions(ctx, cluster, []string{"k"}, []string{"v"}, []uint64{ts})
+	txn, err := client.BeginWithTS(ts)
+	if err != nil {
+		return errToFeatureStatus(err)
+	}
+	t.mustGet(ctx, txn, "k", "v")
+	txn, err = client.BeginWithTS(ts - 1)
+	ctx.AssertNil(err)
+	t.mustNotExist(ctx, txn, "k")
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterFeature("txnkv.gc-safepoint", "reject reads below the GC safe point", []string{"txnkv.snapshot-read"}, testTxnKV{}.checkGCSafePoint)
+
+func (t testTxnKV) checkGCSafePoint(ctx validator.ExecContext) validator.FeatureStatus {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	ts := t.mustGetTS(ctx, client)
+	mustLoadVersions(ctx, cluster, []string{"k"}, []string{"v"}, []uint64{ts})
+	err := cluster.SetGCSafePoint(t.mustGetTS(ctx, client))
+	ctx.AssertNil(err)
+	err = t.snapshotGet(client, ts, "k")
+	if err == nil {
+		ctx.Log("read below the GC safe point succeeds")
+		return validator.FeatureFail
+	}
+	if !isGCSafePointErr(err) {
+		ctx.Log("read below the GC safe point fails with unexpected error: %v", err)
+		return errToFeatureStatus(err)
+	}
+	return validator.FeaturePass
+}
+
+var _ = validator.RegisterStory("snapshot read at timestamp", "txnkv.snapshot-read", "txnkv.gc-safepoint")
+
+var _ = validator.RegisterTest("read versions at past timestamps", []string{"txnkv.batch-get", "txnkv.iter", "txnkv.snapshot-read"}, testTxnKV{}.testSnapshotRead)
+
+func (t testTxnKV) testSnapshotRead(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	var ts []uint64
+	for i := 0; i < 4; i++ {
+		ts = append(ts, t.mustGetTS(ctx, client))
+	}
+	// k1: v1@ts[0], v2@ts[2]; k2: v1@ts[1]; k3: v1@ts[1], deleted@ts[3]
+	mustLoadVersions(ctx, cluster,
+		[]string{"k1", "k1", "k2", "k3", "k3"},
+		[]string{"v1", "v2", "v1", "v1", ""},
+		[]uint64{ts[0], ts[2], ts[1], ts[1], ts[3]})
+
+	check := func(readTS uint64, expect ...string) {
+		ctx.AddCallerDepth(1)
+		defer ctx.AddCallerDepth(-1)
+		txn := t.mustBeginWithTS(ctx, client, readTS)
+		var keys, values, kvs []string
+		for i := 0; i < len(expect); i += 2 {
+			keys, values = append(keys, expect[i]), append(values, expect[i+1])
+			if expect[i+1] != "" {
+				kvs = append(kvs, expect[i], expect[i+1])
+			}
+		}
+		for i := range keys {
+			t.mustGet(ctx, txn, keys[i], values[i])
+		}
+		t.mustBatchGet(ctx, txn, keys, values)
+		t.mustIter(ctx, txn, "k", "", kvs...)
+	}
+	check(ts[0]-1, "k1", "", "k2", "", "k3", "")
+	check(ts[0], "k1", "v1", "k2", "", "k3", "")
+	check(ts[1], "k1", "v1", "k2", "v1", "k3", "v1")
+	check(ts[2]-1, "k1", "v1", "k2", "v1", "k3", "v1")
+	check(ts[2], "k1", "v2", "k2", "v1", "k3", "v1")
+	check(ts[3], "k1", "v2", "k2", "v1", "k3", "")
+	check(t.mustGetTS(ctx, client), "k1", "v2", "k2", "v1", "k3", "")
+}
+
+var _ = validator.RegisterTest("read versions written by client at past timestamps", []string{"txnkv.commit", "txnkv.snapshot-read"}, testTxnKV{}.testSnapshotReadCommitted)
+
+func (t testTxnKV) testSnapshotReadCommitted(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	var ts []uint64
+	for i := 0; i < 3; i++ {
+		txn := t.mustBegin(ctx, client)
+		t.mustSet(ctx, txn, "k", fmt.Sprint("v", i))
+		t.mustCommit(ctx, txn)
+		ts = append(ts, t.mustGetTS(ctx, client))
+	}
+	for i := range ts {
+		t.mustGet(ctx, t.mustBeginWithTS(ctx, client, ts[i]), "k", fmt.Sprint("v", i))
+	}
+}
+
+var _ = validator.RegisterTest("reads below the GC safe point are rejected", []string{"txnkv.gc-safepoint"}, testTxnKV{}.testGCSafePoint)
+
+func (t testTxnKV) testGCSafePoint(ctx validator.ExecContext) {
+	cluster, client := t.newClient(ctx)
+	defer cluster.Close()
+	defer client.Close()
+
+	var ts []uint64
+	for i := 0; i < 3; i++ {
+		ts = append(ts, t.mustGetTS(ctx, client))
+	}
+	mustLoadVersions(ctx, cluster, []string{"k", "k"}, []string{"v1", "v2"}, []uint64{ts[0], ts[2]})
+
+	ctx.AssertNil(cluster.SetGCSafePoint(ts[1]))
+	t.mustGCSafePointErr(ctx, client, ts[0], "k")
+	t.mustGCSafePointErr(ctx, client, ts[1]-1, "k")
+	// Reading at the safe point is allowed, the version before it is kept.
+	t.mustGet(ctx, t.mustBeginWithTS(ctx, client, ts[1]), "k", "v1")
+	t.mustGet(ctx, t.mustBeginWithTS(ctx, client, ts[2]), "k", "v2")
+
+	ctx.AssertNil(cluster.SetGCSafePoint(ts[2]))
+	t.mustGCSafePointErr(ctx, client, ts[1], "k")
+	t.mustGet(ctx, t.mustBeginWithTS(ctx, client, ts[2]), "k", "v2")
+	t.mustGet(ctx, t.mustBegin(ctx, client), "k", "v2")
+}
+
+// snapshotGet reads the key at the timestamp and returns the error, which may
+// be returned by either BeginWithTS or Get.
+func (t testTxnKV) snapshotGet(client stub.TxnKV, ts uint64, key string) error {
+	txn, err := client.BeginWithTS(ts)
+	if err != nil {
+		return err
+	}
+	_, err = txn.Get([]byte(key))
+	return err
+}
+
+// isGCSafePointErr tells whether the error is returned for reading below the GC
+// safe point, e.g. "start ts is older than GC safe point" or "GC life time is
+// shorter than transaction duration".
+func isGCSafePointErr(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "safe point") || strings.Contains(msg, "safepoint") || strings.Contains(msg, "gc life time")
+}
+
+func (t testTxnKV) mustGCSafePointErr(ctx validator.ExecContext, client stub.TxnKV, ts uint64, key string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	err := t.snapshotGet(client, ts, key)
+	ctx.AssertNotNil(err, "read below safe point should fail")
+	ctx.Assert(isGCSafePointErr(err), fmt.Sprintf("expect GC safe point error, got %v", err))
+}
+
+func (t testTxnKV) mustBeginWithTS(ctx validator.ExecContext, client stub.TxnKV, ts uint64) stub.Transaction {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	txn, err := client.BeginWithTS(ts)
+	ctx.AssertNil(err)
+	return txn
+}
@@ -15,16 +15,108 @@ package tests
 
 import (
 	"flag"
+	"fmt"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/tikv/client-validator/mocktikv"
+	"github.com/tikv/client-validator/stub"
 	"github.com/tikv/client-validator/validator"
 )
 
 var (
 	mockTiKVAddr    = flag.String("mock-tikv", "http://127.0.0.1:2378", "mock-tikv server address")
 	clientProxyAddr = flag.String("client-proxy", "http://127.0.0.1:8080", "client proxy server address")
+	proxyTransport  = flag.String("proxy-transport", stub.TransportHTTP, "transport to the client proxy server: http | grpc")
+	clientName      = flag.String("client", stub.ClientProxy, "client to validate: proxy | go-inproc")
+	traceLevel      = flag.String("trace", "errors", "calls to the client proxy recorded in test logs: none | errors | calls | full")
+	callTimeout     = flag.Duration("call-timeout", 10*time.Second, "max time of each call to the client, it is raised above pd-error-timeout")
 )
 
+var callTimeoutOnce sync.Once
+
+// setCallTimeout sets the deadline of the harness for calls to clients. It is
+// kept longer than pdErrorTimeout, so that a client hanging while PD is
+// unreachable is not taken as returning an error in time.
+func setCallTimeout() {
+	callTimeoutOnce.Do(func() {
+		stub.CallTimeout = *callTimeout
+		if d := 2 * *pdErrorTimeout; d > stub.CallTimeout {
+			stub.CallTimeout = d
+		}
+	})
+}
+
+var (
+	transportOnce  sync.Once
+	transport      stub.Transport
+	transportLevel stub.TraceLevel
+	transportErr   error
+)
+
+// getTransport returns the transport to the client proxy server for a new
+// client. The connection is shared by all clients, while calls of the client
+// are traced in the logs of the checker or test creating it.
+func getTransport() (stub.Transport, error) {
+	transportOnce.Do(func() {
+		setCallTimeout()
+		if transportLevel, transportErr = stub.ParseTraceLevel(*traceLevel); transportErr != nil {
+			return
+		}
+		transport, transportErr = stub.NewTransport(*proxyTransport, *clientProxyAddr)
+	})
+	if transportErr != nil {
+		return nil, transportErr
+	}
+	tracer := validator.NewTracer()
+	return stub.NewTraceTransport(transport, transportLevel, tracer.Enabled, func(msg string) {
+		tracer.Trace("%s", msg)
+	}), nil
+}
+
+// newRawClient creates a rawkv client selected by the client flag.
+func newRawClient(pdAddrs []string) (stub.RawKV, error) {
+	if *clientName != stub.ClientProxy {
+		setCallTimeout()
+		driver, err := stub.GetDriver(*clientName)
+		if err != nil {
+			return nil, err
+		}
+		return driver.NewRawKV(pdAddrs)
+	}
+	t, err := getTransport()
+	if err != nil {
+		return nil, err
+	}
+	client, err := stub.NewRawClientStubWithTransport(t, pdAddrs)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// newTxnClient creates a txnkv client selected by the client flag.
+func newTxnClient(pdAddrs []string) (stub.TxnKV, error) {
+	if *clientName != stub.ClientProxy {
+		setCallTimeout()
+		driver, err := stub.GetDriver(*clientName)
+		if err != nil {
+			return nil, err
+		}
+		return driver.NewTxnKV(pdAddrs)
+	}
+	t, err := getTransport()
+	if err != nil {
+		return nil, err
+	}
+	client, err := stub.NewTxnClientStubWithTransport(t, pdAddrs)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
 func errToFeatureStatus(err error) validator.FeatureStatus {
 	if err == nil {
 		return validator.FeaturePass
@@ -34,3 +126,163 @@ func errToFeatureStatus(err error) validator.FeatureStatus {
 	}
 	return validator.FeatureFail
 }
+
+// mustSplit splits regions of the cluster at start and end, so that range
+// [start, end) is covered by separate regions. An empty key means no bound.
+func mustSplit(ctx validator.ExecContext, cluster *mocktikv.Cluster, start, end string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	var keys [][]byte
+	for _, k := range []string{start, end} {
+		if k != "" {
+			keys = append(keys, []byte(k))
+		}
+	}
+	err := cluster.Split(keys...)
+	ctx.AssertNil(err)
+}
+
+func mustAdvanceClock(ctx validator.ExecContext, cluster *mocktikv.Cluster, d time.Duration) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	err := cluster.AdvanceClock(d)
+	ctx.AssertNil(err)
+}
+
+func mustEnableFailpoint(ctx validator.ExecContext, cluster *mocktikv.Cluster, name string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	err := cluster.EnableFailpoint(name)
+	ctx.AssertNil(err)
+}
+
+func mustEnableRegionFailpoint(ctx validator.ExecContext, cluster *mocktikv.Cluster, name string, key string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	err := cluster.EnableRegionFailpoint(name, []byte(key))
+	ctx.AssertNil(err)
+}
+
+func mustDisableFailpoint(ctx validator.ExecContext, cluster *mocktikv.Cluster, name string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	err := cluster.DisableFailpoint(name)
+	ctx.AssertNil(err)
+}
+
+func mustPutLock(ctx validator.ExecContext, cluster *mocktikv.Cluster, lock mocktikv.MockLock) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	err := cluster.PutLock(lock)
+	ctx.AssertNil(err)
+}
+
+func mustResetStats(ctx validator.ExecContext, cluster *mocktikv.Cluster) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	err := cluster.ResetStats()
+	ctx.AssertNil(err)
+}
+
+func mustStats(ctx validator.ExecContext, cluster *mocktikv.Cluster) *mocktikv.MockStats {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	stats, err := cluster.Stats()
+	ctx.AssertNil(err)
+	return stats
+}
+
+// mustCommandsAtMost checks that TiKV servers receive at most n requests of
+// the command.
+func mustCommandsAtMost(ctx validator.ExecContext, stats *mocktikv.MockStats, cmd string, n uint64) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	got := stats.Commands[cmd]
+	ctx.Assert(got <= n, fmt.Sprintf("expect at most %d %s requests, got %d", n, cmd, got))
+}
+
+// mustPDCommandsAtMost checks that PD servers receive at most n requests of
+// the command.
+func mustPDCommandsAtMost(ctx validator.ExecContext, stats *mocktikv.MockStats, cmd string, n uint64) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	got := stats.PDCommands[cmd]
+	ctx.Assert(got <= n, fmt.Sprintf("expect at most %d %s requests to PD, got %d", n, cmd, got))
+}
+
+// mustNoRegionErrors checks that no region error is returned to the client,
+// and no request is retried.
+func mustNoRegionErrors(ctx validator.ExecContext, stats *mocktikv.MockStats) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	ctx.Assert(stats.TotalRegionErrors() == 0, fmt.Sprintf("expect no region errors, got %v", stats.RegionErrors))
+	ctx.Assert(stats.TotalRetries() == 0, fmt.Sprintf("expect no retries, got %v", stats.Retries))
+}
+
+// mustLoad loads key-value pairs into the cluster, kvs are given as key, value,
+// key, value...
+func mustLoad(ctx validator.ExecContext, cluster *mocktikv.Cluster, mode mocktikv.StorageMode, kvs ...string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	ctx.Assert(len(kvs)%2 == 0, fmt.Sprintf("expect key-value pairs to load, got %d strings", len(kvs)))
+	var data []mocktikv.MockKV
+	for i := 0; i < len(kvs); i += 2 {
+		data = append(data, mocktikv.MockKV{Key: []byte(kvs[i]), Value: []byte(kvs[i+1])})
+	}
+	err := cluster.Load(mode, data)
+	ctx.AssertNil(err)
+}
+
+// mustLoadVersions loads versions of keys into the cluster in txn mode, each
+// key is committed at the corresponding timestamp.
+func mustLoadVersions(ctx validator.ExecContext, cluster *mocktikv.Cluster, keys, values []string, commitTS []uint64) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	var data []mocktikv.MockKV
+	for i := range keys {
+		data = append(data, mocktikv.MockKV{Key: []byte(keys[i]), Value: []byte(values[i]), StartTS: commitTS[i] - 1, CommitTS: commitTS[i]})
+	}
+	err := cluster.Load(mocktikv.StorageTxn, data)
+	ctx.AssertNil(err)
+}
+
+// mustDump checks key-value pairs stored in range [start, end) of the cluster,
+// expect is given as key, value, key, value... In txn mode, only the latest
+// version of each key is checked, and deleted keys are ignored.
+func mustDump(ctx validator.ExecContext, cluster *mocktikv.Cluster, mode mocktikv.StorageMode, start, end string, expect ...string) {
+	ctx.AddCallerDepth(1)
+	defer ctx.AddCallerDepth(-1)
+	kvs, err := cluster.Dump(mocktikv.MockRange{Mode: mode, StartKey: []byte(start), EndKey: []byte(end)})
+	ctx.AssertNil(err)
+	if mode == mocktikv.StorageTxn {
+		kvs = latestVersions(kvs)
+	}
+	ctx.AssertEQ(len(kvs)*2, len(expect))
+	for i := range kvs {
+		ctx.AssertEQ(string(kvs[i].Key), expect[i*2])
+		ctx.AssertEQ(string(kvs[i].Value), expect[i*2+1])
+	}
+}
+
+// latestVersions picks the latest version of each key from versions dumped in
+// txn mode, and removes keys that are deleted.
+func latestVersions(kvs []mocktikv.MockKV) []mocktikv.MockKV {
+	var latest []mocktikv.MockKV
+	for i, kv := range kvs {
+		if i > 0 && string(kv.Key) == string(kvs[i-1].Key) {
+			continue
+		}
+		if len(kv.Value) > 0 {
+			latest = append(latest, kv)
+		}
+	}
+	return latest
+}
+
+func bss(ss ...string) [][]byte {
+	bss := make([][]byte, len(ss))
+	for i := range ss {
+		bss[i] = []byte(ss[i])
+	}
+	return bss
+}
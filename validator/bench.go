@@ -0,0 +1,205 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BenchContext contains methods need for benchF.
+type BenchContext interface {
+	ExecContext
+
+	// Loop calls op repeatedly and records its latencies. It runs a warm-up
+	// phase first, whose calls are not recorded. It should be called once by a
+	// benchmark, after setup and before teardown. Calls are not traced.
+	Loop(op func())
+}
+
+// BenchTime is how long a round of benchmark runs, either a duration or a count
+// of operations.
+type BenchTime struct {
+	Duration time.Duration
+	Ops      int // if it is not zero, Duration is ignored
+}
+
+// ParseBenchTime parses a bench time like `go test -benchtime`, e.g. "10s" or
+// "1000x".
+func ParseBenchTime(s string) (BenchTime, error) {
+	if strings.HasSuffix(s, "x") {
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "x"))
+		if err != nil || n <= 0 {
+			return BenchTime{}, errors.Errorf("invalid bench time: %s", s)
+		}
+		return BenchTime{Ops: n}, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return BenchTime{}, errors.Errorf("invalid bench time: %s", s)
+	}
+	return BenchTime{Duration: d}, nil
+}
+
+func (t BenchTime) String() string {
+	if t.Ops > 0 {
+		return fmt.Sprintf("%dx", t.Ops)
+	}
+	return t.Duration.String()
+}
+
+// BenchRound is the result of a round of benchmark.
+type BenchRound struct {
+	Ops        int           `json:"ops"`
+	Duration   time.Duration `json:"duration"`
+	Throughput float64       `json:"throughput"` // operations per second
+	P50        time.Duration `json:"p50"`
+	P99        time.Duration `json:"p99"`
+	P999       time.Duration `json:"p999"`
+}
+
+// BenchReport is the result of a benchmark. Rounds are executed separately,
+// the summary is computed over all rounds.
+type BenchReport struct {
+	Key        string        `json:"key"`
+	Features   []string      `json:"features,omitempty"`
+	Status     FeatureStatus `json:"status"` // PASS, FAIL, or SKIP if required features do not pass
+	Rounds     []BenchRound  `json:"rounds,omitempty"`
+	Throughput float64       `json:"throughput,omitempty"` // mean of rounds
+	Latency    *Histogram    `json:"latency,omitempty"`    // merged of rounds
+	Records    []Recorder    `json:"records,omitempty"`
+}
+
+// RegisterBenchmark defines a benchmark, it is executed only if all features
+// pass or have defects. All benchmarks should be registered before main().
+func RegisterBenchmark(key string, features []string, benchF func(BenchContext)) struct{} {
+	confMu.Lock()
+	defer confMu.Unlock()
+	for _, bench := range benchConfs {
+		if bench.key == key {
+			panic("duplicated benchmark key: " + key)
+		}
+	}
+	benchConfs = append(benchConfs, benchConf{
+		key:      key,
+		features: features,
+		benchF:   benchF,
+	})
+	return struct{}{}
+}
+
+type benchConf struct {
+	key      string
+	features []string
+	benchF   func(BenchContext)
+}
+
+type benchContext struct {
+	execContext
+	opts   *Options
+	looped bool
+	round  BenchRound
+	hist   Histogram
+}
+
+func (c *benchContext) Loop(op func()) {
+	if c.looped {
+		c.Fail("Loop is called more than once")
+	}
+	c.looped = true
+
+	// Tracing every call slows down the benchmark and floods the logs.
+	setActiveRecorder(nil)
+	defer setActiveRecorder(c.Recorder)
+
+	for start := time.Now(); time.Since(start) < c.opts.BenchWarmup; {
+		op()
+	}
+	start := time.Now()
+	for ops := 0; ; ops++ {
+		if c.opts.BenchTime.Ops > 0 && ops >= c.opts.BenchTime.Ops ||
+			c.opts.BenchTime.Ops == 0 && time.Since(start) >= c.opts.BenchTime.Duration {
+			break
+		}
+		t := time.Now()
+		op()
+		c.hist.Add(time.Since(t))
+	}
+	duration := time.Since(start)
+	c.round = BenchRound{
+		Ops:        int(c.hist.Count),
+		Duration:   duration,
+		Throughput: float64(c.hist.Count) / duration.Seconds(),
+		P50:        c.hist.Percentile(0.5),
+		P99:        c.hist.Percentile(0.99),
+		P999:       c.hist.Percentile(0.999),
+	}
+}
+
+func (r *testRunner) runBenchmark(b benchConf) BenchReport {
+	report := BenchReport{Key: b.key, Features: b.features, Status: FeatureSkip}
+	if !r.checkRequiredFeatures(b.features) {
+		return report
+	}
+	report.Status = FeaturePass
+	report.Latency = &Histogram{}
+	for i := 0; i < r.benchCount(); i++ {
+		recorder := newRecorder(fmt.Sprintf("bench %s #%d", b.key, i+1))
+		ctx := &benchContext{
+			execContext: newExecContext(recorder, r.execSeed(b.key)+int64(i)),
+			opts:        &r.opts,
+		}
+		r.callBenchmark(ctx, b.benchF)
+		if recorder.Success && !ctx.looped {
+			recorder.log(false, "Loop is not called")
+			recorder.Success = false
+		}
+		recorder.Log("bench finish. success=%v, ops=%d, throughput=%.2f/s", recorder.Success, ctx.round.Ops, ctx.round.Throughput)
+		report.Records = append(report.Records, *recorder)
+		if !recorder.Success {
+			report.Status = FeatureFail
+			continue
+		}
+		report.Rounds = append(report.Rounds, ctx.round)
+		report.Latency.Merge(&ctx.hist)
+	}
+	for _, round := range report.Rounds {
+		report.Throughput += round.Throughput / float64(len(report.Rounds))
+	}
+	return report
+}
+
+func (r *testRunner) callBenchmark(ctx *benchContext, f func(BenchContext)) {
+	setActiveRecorder(ctx.Recorder)
+	defer setActiveRecorder(nil)
+	defer func() {
+		if err := recover(); err != nil {
+			ctx.Recorder.log(false, "%v", err)
+		}
+	}()
+
+	f(ctx)
+	ctx.Recorder.Success = true // Success if not panic
+}
+
+func (r *testRunner) benchCount() int {
+	if r.opts.BenchCount > 0 {
+		return r.opts.BenchCount
+	}
+	return 1
+}
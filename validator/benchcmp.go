@@ -0,0 +1,244 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"math"
+	"sort"
+)
+
+// Benchmark metrics which are compared.
+const (
+	MetricThroughput = "throughput"
+	MetricP50        = "p50"
+	MetricP99        = "p99"
+)
+
+// MinCompareSamples is the min count of rounds on each side for the comparison
+// of a benchmark to find a significant change at the default significance
+// level. With fewer rounds, the p-value is never less than 0.05.
+const MinCompareSamples = 4
+
+// CompareOptions controls how benchmark results are compared.
+type CompareOptions struct {
+	// Alpha is the significance level, a change is significant if the p-value
+	// is less than it. 0.05 if it is zero.
+	Alpha float64
+	// Threshold is the relative change of a metric to be a regression, e.g.
+	// 0.05 means 5% lower throughput or 5% higher latency.
+	Threshold float64
+}
+
+// BenchComparison is the change of a metric of a benchmark. Samples are the
+// rounds of the benchmark, and values are the medians of samples.
+type BenchComparison struct {
+	Key         string  `json:"key"`
+	Metric      string  `json:"metric"`
+	Old         float64 `json:"old"`
+	New         float64 `json:"new"`
+	Delta       float64 `json:"delta"` // relative change, (new - old) / old
+	P           float64 `json:"p"`     // p-value of Mann-Whitney U test
+	OldSamples  int     `json:"old_samples"`
+	NewSamples  int     `json:"new_samples"`
+	Significant bool    `json:"significant"`
+	Regression  bool    `json:"regression"`
+	// Missing means the benchmark has rounds in the old report but none in
+	// the new one, e.g. all rounds failed. It is taken as a regression.
+	Missing bool `json:"missing,omitempty"`
+}
+
+// CompareBenchmarks compares metrics of benchmarks which have results in the
+// old report. Samples are compared by Mann-Whitney U test like benchstat, so
+// each benchmark needs several rounds (4 or more) to detect a significant
+// change. Benchmarks without results in the new report are regressions.
+func CompareBenchmarks(old, new []BenchReport, opts CompareOptions) []BenchComparison {
+	if opts.Alpha == 0 {
+		opts.Alpha = 0.05
+	}
+	news := make(map[string]*BenchReport, len(new))
+	for i := range new {
+		news[new[i].Key] = &new[i]
+	}
+	var comparisons []BenchComparison
+	for i := range old {
+		o := &old[i]
+		if len(o.Rounds) == 0 {
+			continue
+		}
+		n, ok := news[o.Key]
+		for _, metric := range []string{MetricThroughput, MetricP50, MetricP99} {
+			if !ok || len(n.Rounds) == 0 {
+				samples := benchSamples(o, metric)
+				comparisons = append(comparisons, BenchComparison{
+					Key:        o.Key,
+					Metric:     metric,
+					Old:        median(samples),
+					P:          1,
+					OldSamples: len(samples),
+					Regression: true,
+					Missing:    true,
+				})
+				continue
+			}
+			c := compareSamples(benchSamples(o, metric), benchSamples(n, metric), opts)
+			c.Key, c.Metric = o.Key, metric
+			// Higher throughput is better, while lower latency is better.
+			worse := c.Delta
+			if metric == MetricThroughput {
+				worse = -worse
+			}
+			c.Regression = c.Significant && worse > opts.Threshold
+			comparisons = append(comparisons, c)
+		}
+	}
+	return comparisons
+}
+
+func benchSamples(b *BenchReport, metric string) []float64 {
+	samples := make([]float64, 0, len(b.Rounds))
+	for _, r := range b.Rounds {
+		switch metric {
+		case MetricThroughput:
+			samples = append(samples, r.Throughput)
+		case MetricP50:
+			samples = append(samples, float64(r.P50))
+		case MetricP99:
+			samples = append(samples, float64(r.P99))
+		}
+	}
+	return samples
+}
+
+func compareSamples(old, new []float64, opts CompareOptions) BenchComparison {
+	c := BenchComparison{
+		Old:        median(old),
+		New:        median(new),
+		P:          MannWhitneyU(old, new),
+		OldSamples: len(old),
+		NewSamples: len(new),
+	}
+	if c.Old != 0 {
+		c.Delta = (c.New - c.Old) / c.Old
+	}
+	c.Significant = c.P < opts.Alpha
+	return c
+}
+
+func median(samples []float64) float64 {
+	s := append([]float64(nil), samples...)
+	sort.Float64s(s)
+	if len(s) == 0 {
+		return 0
+	}
+	if len(s)%2 == 1 {
+		return s[len(s)/2]
+	}
+	return (s[len(s)/2-1] + s[len(s)/2]) / 2
+}
+
+// MannWhitneyU returns the two-sided p-value of Mann-Whitney U test, which
+// tells how likely samples x and y are from the same distribution. The exact
+// distribution of U is used for small samples without ties, otherwise the
+// normal approximation with tie correction is used.
+func MannWhitneyU(x, y []float64) float64 {
+	n1, n2 := len(x), len(y)
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+	// Rank all samples, tied samples get the average of their ranks.
+	type sample struct {
+		v     float64
+		fromX bool
+	}
+	all := make([]sample, 0, n1+n2)
+	for _, v := range x {
+		all = append(all, sample{v, true})
+	}
+	for _, v := range y {
+		all = append(all, sample{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+	var rankX, tieCorrection float64
+	hasTies := false
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2 // average of ranks i+1 .. j
+		for k := i; k < j; k++ {
+			if all[k].fromX {
+				rankX += rank
+			}
+		}
+		if t := float64(j - i); t > 1 {
+			hasTies = true
+			tieCorrection += t*t*t - t
+		}
+		i = j
+	}
+	u := rankX - float64(n1*(n1+1))/2
+	// Use the smaller U for the two-sided test.
+	u = math.Min(u, float64(n1*n2)-u)
+
+	if !hasTies && n1+n2 <= 50 {
+		return math.Min(1, 2*exactUCDF(int(u), n1, n2))
+	}
+	n := float64(n1 + n2)
+	mean := float64(n1*n2) / 2
+	variance := float64(n1*n2) / 12 * ((n + 1) - tieCorrection/(n*(n-1)))
+	if variance == 0 {
+		return 1
+	}
+	// Continuity correction towards the mean.
+	z := (u + 0.5 - mean) / math.Sqrt(variance)
+	return math.Min(1, 2*normalCDF(z))
+}
+
+// exactUCDF returns P(U <= u) for samples of size n1 and n2 without ties.
+func exactUCDF(u, n1, n2 int) float64 {
+	// counts[i][j][k] is the count of arrangements of i x's and j y's with U=k,
+	// computed by f(i, j, k) = f(i-1, j, k-j) + f(i, j-1, k).
+	counts := make([][][]float64, n1+1)
+	for i := range counts {
+		counts[i] = make([][]float64, n2+1)
+		for j := range counts[i] {
+			counts[i][j] = make([]float64, i*j+1)
+			if i == 0 || j == 0 {
+				counts[i][j][0] = 1
+				continue
+			}
+			for k := range counts[i][j] {
+				if k-j >= 0 && k-j < len(counts[i-1][j]) {
+					counts[i][j][k] += counts[i-1][j][k-j]
+				}
+				if k < len(counts[i][j-1]) {
+					counts[i][j][k] += counts[i][j-1][k]
+				}
+			}
+		}
+	}
+	var below, total float64
+	for k, c := range counts[n1][n2] {
+		if k <= u {
+			below += c
+		}
+		total += c
+	}
+	return below / total
+}
+
+func normalCDF(z float64) float64 {
+	return 0.5 * math.Erfc(-z/math.Sqrt2)
+}
@@ -78,6 +78,28 @@ func RegisterTest(description string, features []string, testF func(ExecContext)
 	return struct{}{}
 }
 
+func isRegisteredFeature(key string) bool {
+	confMu.RLock()
+	defer confMu.RUnlock()
+	for _, feature := range featureConfs {
+		if feature.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func isRegisteredTest(description string) bool {
+	confMu.RLock()
+	defer confMu.RUnlock()
+	for _, test := range testConfs {
+		if test.description == description {
+			return true
+		}
+	}
+	return false
+}
+
 type featureConf struct {
 	key              string
 	description      string
@@ -101,4 +123,6 @@ var (
 	featureConfs []featureConf
 	testConfs    []testConf
 	storyConfs   []storyConf
+	benchConfs   []benchConf
+	soakConfs    []soakConf
 )
@@ -15,10 +15,12 @@ package validator
 
 import (
 	"fmt"
+	"math/rand"
 	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -34,6 +36,10 @@ type ExecContext interface {
 	AssertNE(x, y interface{}, msg ...string)
 	AssertDeepEQ(x, y interface{}, msg ...string)
 	AddCallerDepth(n int)
+
+	// Rand returns a random source derived from the seed of the run. Any
+	// randomized workload should use it so that the run can be replayed.
+	Rand() *rand.Rand
 }
 
 // Recorder records a execute history of checker or test.
@@ -53,19 +59,79 @@ func (r *Recorder) Log(format string, args ...interface{}) {
 }
 
 func (r *Recorder) log(logCaller bool, format string, args ...interface{}) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	r.logLocked(logCaller, format, args...)
+}
+
+func (r *Recorder) logLocked(logCaller bool, format string, args ...interface{}) {
 	var builder strings.Builder
 	builder.WriteString(time.Now().Format(LogTimeFormat))
 	if logCaller {
 		builder.WriteByte(' ')
-		builder.WriteString(caller(3))
+		builder.WriteString(caller(4))
 	}
 	builder.WriteByte(' ')
 	builder.WriteString(fmt.Sprintf(format, args...))
 	r.Logs = append(r.Logs, builder.String())
 }
 
+var (
+	// logMu protects logs of recorders, which may be written by goroutines of
+	// a test and by tracers.
+	logMu sync.Mutex
+	// activeRecorder is the Recorder of the running checker or test.
+	activeRecorder *Recorder
+)
+
+// Tracer records messages in the Recorder of the checker or test running when
+// it is created. It is used to record events out of an ExecContext, such as
+// calls to clients created by the checker or test.
+type Tracer struct {
+	recorder *Recorder
+}
+
+// NewTracer creates a Tracer of the running checker or test. Messages are
+// dropped if no checker or test is running.
+func NewTracer() *Tracer {
+	logMu.Lock()
+	defer logMu.Unlock()
+	return &Tracer{recorder: activeRecorder}
+}
+
+// Trace records a message. The message is dropped if the checker or test has
+// returned, e.g. it is traced by a goroutine left running, or if tracing is
+// paused while a benchmark is measured.
+func (t *Tracer) Trace(format string, args ...interface{}) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	if t.enabledLocked() {
+		t.recorder.logLocked(false, format, args...)
+	}
+}
+
+// Enabled tells whether messages passed to Trace are recorded. Callers may
+// check it to skip formatting messages which would be dropped.
+func (t *Tracer) Enabled() bool {
+	logMu.Lock()
+	defer logMu.Unlock()
+	return t.enabledLocked()
+}
+
+func (t *Tracer) enabledLocked() bool {
+	return t.recorder != nil && t.recorder == activeRecorder
+}
+
+func setActiveRecorder(r *Recorder) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	activeRecorder = r
+}
+
 // copy returns a copy that is safe to append logs.
 func (r *Recorder) copy() *Recorder {
+	logMu.Lock()
+	defer logMu.Unlock()
 	return &Recorder{
 		Description: r.Description,
 		Logs:        r.Logs[:len(r.Logs):len(r.Logs)],
@@ -143,4 +209,17 @@ func caller(n int) string {
 type execContext struct {
 	*Recorder
 	*asserter
+	rand *rand.Rand
+}
+
+func newExecContext(recorder *Recorder, seed int64) execContext {
+	return execContext{
+		Recorder: recorder,
+		asserter: &asserter{},
+		rand:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (c execContext) Rand() *rand.Rand {
+	return c.rand
 }
@@ -0,0 +1,119 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// histogramSubBuckets is the count of buckets between two powers of 2. Bucket
+// bounds grow by 2^(1/8), so percentiles are accurate within ~9%.
+const histogramSubBuckets = 8
+
+// HistogramBucket counts latencies no larger than the upper bound and larger
+// than the bound of the previous bucket.
+type HistogramBucket struct {
+	UpperBound time.Duration `json:"le"`
+	Count      uint64        `json:"count"`
+}
+
+// Histogram records the distribution of latencies. Only non-empty buckets are
+// kept, ordered by upper bounds.
+type Histogram struct {
+	Count   uint64            `json:"count"`
+	Sum     time.Duration     `json:"sum"`
+	Min     time.Duration     `json:"min"`
+	Max     time.Duration     `json:"max"`
+	Buckets []HistogramBucket `json:"buckets,omitempty"`
+}
+
+// Add records a latency.
+func (h *Histogram) Add(d time.Duration) {
+	if d < 1 {
+		d = 1
+	}
+	if h.Count == 0 || d < h.Min {
+		h.Min = d
+	}
+	if d > h.Max {
+		h.Max = d
+	}
+	h.Count++
+	h.Sum += d
+	h.addBucket(bucketUpperBound(d), 1)
+}
+
+// Merge adds all latencies recorded by another histogram.
+func (h *Histogram) Merge(other *Histogram) {
+	if other.Count == 0 {
+		return
+	}
+	if h.Count == 0 || other.Min < h.Min {
+		h.Min = other.Min
+	}
+	if other.Max > h.Max {
+		h.Max = other.Max
+	}
+	h.Count += other.Count
+	h.Sum += other.Sum
+	for _, b := range other.Buckets {
+		h.addBucket(b.UpperBound, b.Count)
+	}
+}
+
+// Mean returns the average latency.
+func (h *Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Percentile returns the latency which q (in [0, 1]) of latencies are no
+// larger than. It is the upper bound of a bucket, capped by the max latency.
+func (h *Histogram) Percentile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q * float64(h.Count)))
+	var n uint64
+	for _, b := range h.Buckets {
+		n += b.Count
+		if n >= rank {
+			if b.UpperBound > h.Max {
+				return h.Max
+			}
+			return b.UpperBound
+		}
+	}
+	return h.Max
+}
+
+func (h *Histogram) addBucket(upperBound time.Duration, count uint64) {
+	i := sort.Search(len(h.Buckets), func(i int) bool { return h.Buckets[i].UpperBound >= upperBound })
+	if i < len(h.Buckets) && h.Buckets[i].UpperBound == upperBound {
+		h.Buckets[i].Count += count
+		return
+	}
+	h.Buckets = append(h.Buckets, HistogramBucket{})
+	copy(h.Buckets[i+1:], h.Buckets[i:])
+	h.Buckets[i] = HistogramBucket{UpperBound: upperBound, Count: count}
+}
+
+func bucketUpperBound(d time.Duration) time.Duration {
+	i := math.Ceil(math.Log2(float64(d)) * histogramSubBuckets)
+	return time.Duration(math.Ceil(math.Pow(2, i/histogramSubBuckets)))
+}
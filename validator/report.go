@@ -13,6 +13,11 @@
 
 package validator
 
+import "time"
+
+// Version is the version of client-validator. It is set at build time.
+var Version = "unknown"
+
 // FeatureReport is test report for a feature.
 type FeatureReport struct {
 	Key         string        `json:"key"`
@@ -27,10 +32,81 @@ type StoryReport struct {
 	Features    []FeatureReport `json:"features,omitempty"`
 }
 
+// Manifest records how a run is performed. It contains everything needed to
+// replay the run.
+type Manifest struct {
+	Seed             int64             `json:"seed"`
+	ValidatorVersion string            `json:"validator_version,omitempty"`
+	ProxyAddr        string            `json:"proxy_addr,omitempty"`
+	MockTiKVAddr     string            `json:"mock_tikv_addr,omitempty"`
+	ClientName       string            `json:"client_name,omitempty"`
+	ClientLanguage   string            `json:"client_language,omitempty"`
+	ClientVersion    string            `json:"client_version,omitempty"`
+	ClientGitCommit  string            `json:"client_git_commit,omitempty"`
+	Capabilities     []string          `json:"capabilities,omitempty"` // declared by the client
+	StartTime        time.Time         `json:"start_time"`
+	EndTime          time.Time         `json:"end_time"`
+	Flags            map[string]string `json:"flags,omitempty"`
+	Features         []string          `json:"features,omitempty"`   // checked features
+	Tests            []string          `json:"tests,omitempty"`      // executed tests
+	Benchmarks       []string          `json:"benchmarks,omitempty"` // executed benchmarks
+	Soaks            []string          `json:"soaks,omitempty"`      // executed soaks
+}
+
+// CapabilityMismatch is a feature whose status does not agree with the
+// capabilities declared by the client.
+type CapabilityMismatch struct {
+	Key     string        `json:"key"`
+	Status  FeatureStatus `json:"status,omitempty"` // empty if the feature is unknown
+	Claimed bool          `json:"claimed"`
+}
+
 // Report contains test results.
 type Report struct {
-	Stories  []StoryReport   `json:"stories,omitempty"`
-	Features []FeatureReport `json:"features,omitempty"`
+	Manifest   *Manifest            `json:"manifest,omitempty"`
+	Stories    []StoryReport        `json:"stories,omitempty"`
+	Features   []FeatureReport      `json:"features,omitempty"`
+	Mismatches []CapabilityMismatch `json:"mismatches,omitempty"`
+	Benchmarks []BenchReport        `json:"benchmarks,omitempty"`
+	Soaks      []SoakReport         `json:"soaks,omitempty"`
+}
+
+// CheckCapabilities compares status of features with the capabilities
+// declared by the client. Features that are claimed but not working, features
+// that are implemented but not claimed, and claimed capabilities that match no
+// registered feature are recorded as mismatches.
+func (r *Report) CheckCapabilities(capabilities []string) {
+	claimed := make(map[string]bool, len(capabilities))
+	for _, c := range capabilities {
+		claimed[c] = true
+	}
+	check := func(f *FeatureReport) {
+		switch f.Status {
+		case FeatureFail, FeatureDefect, FeatureNotImplemented:
+			if claimed[f.Key] {
+				r.Mismatches = append(r.Mismatches, CapabilityMismatch{Key: f.Key, Status: f.Status, Claimed: true})
+			}
+		}
+		switch f.Status {
+		case FeaturePass, FeatureDefect:
+			if !claimed[f.Key] {
+				r.Mismatches = append(r.Mismatches, CapabilityMismatch{Key: f.Key, Status: f.Status})
+			}
+		}
+	}
+	for i := range r.Stories {
+		for j := range r.Stories[i].Features {
+			check(&r.Stories[i].Features[j])
+		}
+	}
+	for i := range r.Features {
+		check(&r.Features[i])
+	}
+	for _, c := range capabilities {
+		if !isRegisteredFeature(c) {
+			r.Mismatches = append(r.Mismatches, CapabilityMismatch{Key: c, Claimed: true})
+		}
+	}
 }
 
 func (r *testRunner) report() Report {
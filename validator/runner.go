@@ -13,14 +13,90 @@
 
 package validator
 
-import "fmt"
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Options controls which checkers and tests are executed in a run.
+type Options struct {
+	// Seed is used to derive the random source of every checker and test.
+	Seed int64
+	// Features limits the run to the given features and the features they
+	// require. All features are checked if it is empty.
+	Features []string
+	// Tests limits the run to the tests with the given descriptions. If it is
+	// empty, all tests that only cover selected features are executed.
+	Tests []string
+	// Bench selects benchmarks to run by keys, like `go test -bench`. No
+	// benchmark is run if it is nil. Benchmarks are run after all tests.
+	Bench *regexp.Regexp
+	// BenchTime is how long each round of a benchmark runs, 1s if it is zero.
+	BenchTime BenchTime
+	// BenchWarmup is how long a benchmark runs before it is measured.
+	BenchWarmup time.Duration
+	// BenchCount is the count of rounds of each benchmark, 1 if it is zero.
+	BenchCount int
+	// Soak selects soaks to run by keys. No soak is run if it is nil. Soaks
+	// are run after all tests and benchmarks.
+	Soak *regexp.Regexp
+	// SoakDuration is how long each soak runs.
+	SoakDuration time.Duration
+	// SoakWindow is the length of windows in the timeline of soaks, 1m if it
+	// is zero.
+	SoakWindow time.Duration
+	// SoakCheckGoroutines counts goroutines of the process in soaks to find
+	// leaks. It only makes sense if the client runs in the process.
+	SoakCheckGoroutines bool
+}
+
+// Validate checks that features and tests selected by the options are
+// registered. They may be renamed or removed since a replayed report is made.
+func (o Options) Validate() error {
+	for _, key := range o.Features {
+		if !isRegisteredFeature(key) {
+			return errors.Errorf("feature not found: %s", key)
+		}
+	}
+	for _, description := range o.Tests {
+		if !isRegisteredTest(description) {
+			return errors.Errorf("test not found: %s", description)
+		}
+	}
+	return nil
+}
 
 // RunAll runs all registered checkers and tests then determine status of
 // features.
 func RunAll() Report {
-	runner := newTestRunner()
+	return Run(Options{Seed: time.Now().UnixNano()})
+}
+
+// Run runs checkers and tests selected by opts then determine status of
+// features. The returned report contains a manifest that can be used to
+// replay the run.
+func Run(opts Options) Report {
+	runner := newTestRunner(opts)
+	startTime := time.Now()
 	runner.run()
-	return runner.report()
+	report := runner.report()
+	report.Benchmarks = runner.runBenchmarks()
+	report.Soaks = runner.runSoaks()
+	report.Manifest = &Manifest{
+		Seed:             opts.Seed,
+		ValidatorVersion: Version,
+		StartTime:        startTime,
+		EndTime:          time.Now(),
+		Features:         runner.featureKeys(),
+		Tests:            runner.testDescriptions(),
+		Benchmarks:       runner.benchmarkKeys(),
+		Soaks:            runner.soakKeys(),
+	}
+	return report
 }
 
 type featureInfo struct {
@@ -30,23 +106,34 @@ type featureInfo struct {
 }
 
 type testRunner struct {
+	seed        int64
+	opts        Options
 	features    []*featureInfo
 	featuresMap map[string]*featureInfo
 	stories     []storyConf
 	tests       []testConf
+	benchmarks  []benchConf
+	soaks       []soakConf
 }
 
-func newTestRunner() *testRunner {
+func newTestRunner(opts Options) *testRunner {
 	confMu.Lock()
 	defer confMu.Unlock()
 
+	if opts.BenchTime == (BenchTime{}) {
+		opts.BenchTime = BenchTime{Duration: time.Second}
+	}
 	runner := &testRunner{
+		seed:        opts.Seed,
+		opts:        opts,
 		featuresMap: make(map[string]*featureInfo),
-		stories:     append(storyConfs[:0:0], storyConfs...),
-		tests:       append(testConfs[:0:0], testConfs...),
 	}
 
+	selected := selectFeatures(opts.Features, opts.Tests)
 	for _, conf := range featureConfs {
+		if selected != nil && !selected[conf.key] {
+			continue
+		}
 		f := &featureInfo{
 			conf:   conf,
 			status: FeatureSkip,
@@ -54,9 +141,141 @@ func newTestRunner() *testRunner {
 		runner.features = append(runner.features, f)
 		runner.featuresMap[conf.key] = f
 	}
+
+	for _, s := range storyConfs {
+		story := storyConf{description: s.description}
+		for _, key := range s.features {
+			if _, ok := runner.featuresMap[key]; ok {
+				story.features = append(story.features, key)
+			}
+		}
+		if len(story.features) > 0 {
+			runner.stories = append(runner.stories, story)
+		}
+	}
+
+	tests := make(map[string]bool, len(opts.Tests))
+	for _, t := range opts.Tests {
+		tests[t] = true
+	}
+	for _, t := range testConfs {
+		if len(opts.Tests) > 0 && !tests[t.description] {
+			continue
+		}
+		if !runner.containsFeatures(t.features) {
+			continue
+		}
+		runner.tests = append(runner.tests, t)
+	}
+
+	for _, b := range benchConfs {
+		if opts.Bench == nil || !opts.Bench.MatchString(b.key) {
+			continue
+		}
+		if !runner.containsFeatures(b.features) {
+			continue
+		}
+		runner.benchmarks = append(runner.benchmarks, b)
+	}
+
+	for _, s := range soakConfs {
+		if opts.Soak == nil || !opts.Soak.MatchString(s.key) {
+			continue
+		}
+		if !runner.containsFeatures(s.features) {
+			continue
+		}
+		runner.soaks = append(runner.soaks, s)
+	}
 	return runner
 }
 
+// selectFeatures returns the set of features need to be checked for the given
+// features and tests, including the features they require. It returns nil if
+// all features should be checked.
+func selectFeatures(features, tests []string) map[string]bool {
+	if len(features) == 0 && len(tests) == 0 {
+		return nil
+	}
+	confs := make(map[string]featureConf, len(featureConfs))
+	for _, conf := range featureConfs {
+		confs[conf.key] = conf
+	}
+	selected := make(map[string]bool)
+	var add func(key string)
+	add = func(key string) {
+		if selected[key] {
+			return
+		}
+		conf, ok := confs[key]
+		if !ok {
+			panic("feature not found: " + key)
+		}
+		selected[key] = true
+		for _, k := range conf.requiredFeatures {
+			add(k)
+		}
+	}
+	for _, key := range features {
+		add(key)
+	}
+	if len(tests) > 0 {
+		descriptions := make(map[string]bool, len(tests))
+		for _, t := range tests {
+			descriptions[t] = true
+		}
+		for _, t := range testConfs {
+			if descriptions[t.description] {
+				for _, key := range t.features {
+					add(key)
+				}
+			}
+		}
+	}
+	return selected
+}
+
+func (r *testRunner) containsFeatures(features []string) bool {
+	for _, key := range features {
+		if _, ok := r.featuresMap[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *testRunner) featureKeys() []string {
+	keys := make([]string, 0, len(r.features))
+	for _, f := range r.features {
+		keys = append(keys, f.conf.key)
+	}
+	return keys
+}
+
+func (r *testRunner) testDescriptions() []string {
+	descriptions := make([]string, 0, len(r.tests))
+	for _, t := range r.tests {
+		descriptions = append(descriptions, t.description)
+	}
+	return descriptions
+}
+
+func (r *testRunner) benchmarkKeys() []string {
+	keys := make([]string, 0, len(r.benchmarks))
+	for _, b := range r.benchmarks {
+		keys = append(keys, b.key)
+	}
+	return keys
+}
+
+func (r *testRunner) soakKeys() []string {
+	keys := make([]string, 0, len(r.soaks))
+	for _, s := range r.soaks {
+		keys = append(keys, s.key)
+	}
+	return keys
+}
+
 func (r *testRunner) run() {
 	for f := r.nextFeature(); f != nil; f = r.nextFeature() {
 		r.runFeatureChecker(f)
@@ -68,6 +287,22 @@ func (r *testRunner) run() {
 	}
 }
 
+func (r *testRunner) runBenchmarks() []BenchReport {
+	var reports []BenchReport
+	for _, b := range r.benchmarks {
+		reports = append(reports, r.runBenchmark(b))
+	}
+	return reports
+}
+
+func (r *testRunner) runSoaks() []SoakReport {
+	var reports []SoakReport
+	for _, s := range r.soaks {
+		reports = append(reports, r.runSoak(s))
+	}
+	return reports
+}
+
 func (r *testRunner) nextFeature() *featureInfo {
 	for _, f := range r.features {
 		if f.status == FeatureSkip && r.checkRequiredFeatures(f.conf.requiredFeatures) {
@@ -90,14 +325,24 @@ func (r *testRunner) checkRequiredFeatures(features []string) bool {
 	return true
 }
 
+// execSeed derives the seed of a checker or test from the seed of the run, so
+// that it does not depend on which other checkers or tests are executed.
+func (r *testRunner) execSeed(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return r.seed ^ int64(h.Sum64())
+}
+
 func (r *testRunner) runFeatureChecker(f *featureInfo) {
 	recorder := newRecorder(fmt.Sprintf("check %s(%s)", f.conf.key, f.conf.description))
-	f.status = r.callChecker(recorder, f.conf.checkF)
+	f.status = r.callChecker(recorder, r.execSeed(f.conf.key), f.conf.checkF)
 	recorder.Log("check finish. success=%v, feature.status=%s", recorder.Success, f.status)
 	f.records = append(f.records, *recorder)
 }
 
-func (r *testRunner) callChecker(recorder *Recorder, f func(ExecContext) FeatureStatus) (status FeatureStatus) {
+func (r *testRunner) callChecker(recorder *Recorder, seed int64, f func(ExecContext) FeatureStatus) (status FeatureStatus) {
+	setActiveRecorder(recorder)
+	defer setActiveRecorder(nil)
 	defer func() {
 		if err := recover(); err != nil {
 			recorder.log(false, "%v", err)
@@ -106,7 +351,7 @@ func (r *testRunner) callChecker(recorder *Recorder, f func(ExecContext) Feature
 	}()
 
 	if f != nil {
-		status = f(execContext{Recorder: recorder, asserter: &asserter{}})
+		status = f(newExecContext(recorder, seed))
 		recorder.Success = (status == FeaturePass || status == FeatureNotImplemented)
 	} else {
 		status = FeatureSkip
@@ -116,7 +361,7 @@ func (r *testRunner) callChecker(recorder *Recorder, f func(ExecContext) Feature
 
 func (r *testRunner) runTest(t testConf) {
 	recorder := newRecorder(t.description)
-	r.callTest(recorder, t.testF)
+	r.callTest(recorder, r.execSeed(t.description), t.testF)
 	for _, key := range t.features {
 		f := r.featuresMap[key]
 		if !recorder.Success && f.status == FeaturePass {
@@ -128,7 +373,9 @@ func (r *testRunner) runTest(t testConf) {
 	}
 }
 
-func (r *testRunner) callTest(recorder *Recorder, f func(ExecContext)) {
+func (r *testRunner) callTest(recorder *Recorder, seed int64, f func(ExecContext)) {
+	setActiveRecorder(recorder)
+	defer setActiveRecorder(nil)
 	defer func() {
 		if err := recover(); err != nil {
 			recorder.log(false, "%v", err)
@@ -136,7 +383,7 @@ func (r *testRunner) callTest(recorder *Recorder, f func(ExecContext)) {
 	}()
 
 	if f != nil {
-		f(execContext{Recorder: recorder, asserter: &asserter{}})
+		f(newExecContext(recorder, seed))
 		recorder.Success = true // Success if not panic
 	}
 }
@@ -0,0 +1,266 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validator
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// SoakContext contains methods need for soakF. Unlike ExecContext, its
+// methods except assertions are safe to be called by multiple goroutines.
+type SoakContext interface {
+	ExecContext
+
+	// Done is closed when the soak duration passes, workloads should stop then.
+	// soakF should wait for its goroutines to exit before it returns.
+	Done() <-chan struct{}
+	// Record records an operation of workloads into the timeline. Errors are
+	// expected when faults are injected, they are reported but do not fail
+	// the soak.
+	Record(op string, latency time.Duration, err error)
+	// Event records a fault injected into the cluster.
+	Event(format string, args ...interface{})
+	// Anomaly records a violation found by consistency checks. The soak fails
+	// if any anomaly is found.
+	Anomaly(format string, args ...interface{})
+}
+
+// SoakEvent is a fault or an anomaly happened in a soak.
+type SoakEvent struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// SoakWindow is the statistics of operations in a window of the timeline.
+type SoakWindow struct {
+	Start      time.Time         `json:"start"`
+	Ops        map[string]uint64 `json:"ops,omitempty"`    // by operation
+	Errors     map[string]uint64 `json:"errors,omitempty"` // by operation
+	Latency    *Histogram        `json:"latency,omitempty"`
+	Goroutines int               `json:"goroutines,omitempty"` // count at the end of the window, if checked
+	Anomalies  int               `json:"anomalies,omitempty"`
+	// ErrorSamples are the first distinct errors in the window.
+	ErrorSamples []string `json:"error_samples,omitempty"`
+}
+
+// SoakReport is the timeline of a soak.
+type SoakReport struct {
+	Key       string        `json:"key"`
+	Features  []string      `json:"features,omitempty"`
+	Status    FeatureStatus `json:"status"` // PASS, FAIL, or SKIP if required features do not pass
+	Timeline  []SoakWindow  `json:"timeline,omitempty"`
+	Events    []SoakEvent   `json:"events,omitempty"`
+	Anomalies []SoakEvent   `json:"anomalies,omitempty"`
+	Records   []Recorder    `json:"records,omitempty"`
+}
+
+// soakErrorSamples is the max count of error samples in a window.
+const soakErrorSamples = 5
+
+// soakGrowthWindows is the count of consecutive windows in which goroutines
+// stay above the baseline to be reported as a leak.
+const soakGrowthWindows = 5
+
+// soakGoroutineSlackRatio and soakGoroutineSlack are the growth of goroutines
+// over the baseline tolerated, e.g. for pools growing with the load. They are
+// relative to the baseline and absolute.
+const soakGoroutineSlackRatio, soakGoroutineSlack = 0.5, 16
+
+// RegisterSoak defines a long-running workload, it is executed only if all
+// features pass or have defects. All soaks should be registered before main().
+func RegisterSoak(key string, features []string, soakF func(SoakContext)) struct{} {
+	confMu.Lock()
+	defer confMu.Unlock()
+	for _, soak := range soakConfs {
+		if soak.key == key {
+			panic("duplicated soak key: " + key)
+		}
+	}
+	soakConfs = append(soakConfs, soakConf{
+		key:      key,
+		features: features,
+		soakF:    soakF,
+	})
+	return struct{}{}
+}
+
+type soakConf struct {
+	key      string
+	features []string
+	soakF    func(SoakContext)
+}
+
+type soakContext struct {
+	execContext
+	start           time.Time
+	window          time.Duration
+	done            chan struct{}
+	checkGoroutines bool
+
+	mu           sync.Mutex
+	timeline     []SoakWindow
+	report       *SoakReport
+	leakReported bool
+}
+
+func (c *soakContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *soakContext) Record(op string, latency time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := c.currentWindow()
+	w.Ops[op]++
+	w.Latency.Add(latency)
+	if err != nil {
+		w.Errors[op]++
+		msg := fmt.Sprintf("%s: %v", op, err)
+		for _, s := range w.ErrorSamples {
+			if s == msg {
+				return
+			}
+		}
+		if len(w.ErrorSamples) < soakErrorSamples {
+			w.ErrorSamples = append(w.ErrorSamples, msg)
+		}
+	}
+}
+
+func (c *soakContext) Event(format string, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.report.Events = append(c.report.Events, SoakEvent{Time: time.Now(), Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *soakContext) Anomaly(format string, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.currentWindow().Anomalies++
+	c.report.Anomalies = append(c.report.Anomalies, SoakEvent{Time: time.Now(), Message: fmt.Sprintf(format, args...)})
+}
+
+// currentWindow returns the window of now, windows before it are closed.
+func (c *soakContext) currentWindow() *SoakWindow {
+	i := int(time.Since(c.start) / c.window)
+	for len(c.timeline) <= i {
+		if n := len(c.timeline); n > 0 && c.checkGoroutines {
+			c.timeline[n-1].Goroutines = runtime.NumGoroutine()
+			c.checkGoroutineGrowth()
+		}
+		c.timeline = append(c.timeline, SoakWindow{
+			Start:   c.start.Add(time.Duration(len(c.timeline)) * c.window),
+			Ops:     make(map[string]uint64),
+			Errors:  make(map[string]uint64),
+			Latency: &Histogram{},
+		})
+	}
+	return &c.timeline[i]
+}
+
+// checkGoroutineGrowth reports an anomaly if goroutines stay above the count
+// of the first window with slack in the last soakGrowthWindows windows, which
+// usually means goroutines or connections leak, even if the count drops from
+// time to time. It is reported once for a soak.
+func (c *soakContext) checkGoroutineGrowth() {
+	n := len(c.timeline)
+	if c.leakReported || n <= soakGrowthWindows {
+		return
+	}
+	baseline := c.timeline[0].Goroutines
+	limit := baseline + int(float64(baseline)*soakGoroutineSlackRatio) + soakGoroutineSlack
+	for k := n - soakGrowthWindows; k < n; k++ {
+		if c.timeline[k].Goroutines <= limit {
+			return
+		}
+	}
+	c.leakReported = true
+	w := &c.timeline[n-1]
+	w.Anomalies++
+	c.report.Anomalies = append(c.report.Anomalies, SoakEvent{
+		Time:    time.Now(),
+		Message: fmt.Sprintf("goroutines grow over %d in %d windows: %d -> %d", limit, soakGrowthWindows, baseline, w.Goroutines),
+	})
+}
+
+// sampleWindows closes windows in time even if no operation is recorded, so
+// that goroutines are counted in every window.
+func (c *soakContext) sampleWindows(stop <-chan struct{}) {
+	ticker := time.NewTicker(c.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			c.mu.Lock()
+			c.currentWindow()
+			c.mu.Unlock()
+		}
+	}
+}
+
+func (r *testRunner) runSoak(s soakConf) SoakReport {
+	report := SoakReport{Key: s.key, Features: s.features, Status: FeatureSkip}
+	if !r.checkRequiredFeatures(s.features) {
+		return report
+	}
+	recorder := newRecorder("soak " + s.key)
+	ctx := &soakContext{
+		execContext:     newExecContext(recorder, r.execSeed(s.key)),
+		start:           time.Now(),
+		window:          r.opts.SoakWindow,
+		done:            make(chan struct{}),
+		checkGoroutines: r.opts.SoakCheckGoroutines,
+		report:          &report,
+	}
+	if ctx.window <= 0 {
+		ctx.window = time.Minute
+	}
+	timer := time.AfterFunc(r.opts.SoakDuration, func() { close(ctx.done) })
+	defer timer.Stop()
+	stop := make(chan struct{})
+	go ctx.sampleWindows(stop)
+	r.callSoak(ctx, s.soakF)
+	close(stop)
+
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	if ctx.checkGoroutines {
+		ctx.currentWindow().Goroutines = runtime.NumGoroutine()
+	}
+	report.Timeline = ctx.timeline
+	report.Status = FeaturePass
+	if !recorder.Success || len(report.Anomalies) > 0 {
+		report.Status = FeatureFail
+	}
+	recorder.Log("soak finish. success=%v, anomalies=%d, soak.status=%s", recorder.Success, len(report.Anomalies), report.Status)
+	report.Records = []Recorder{*recorder}
+	return report
+}
+
+// callSoak runs a soak without tracing, as calls of hours would flood the logs.
+func (r *testRunner) callSoak(ctx *soakContext, f func(SoakContext)) {
+	defer func() {
+		if err := recover(); err != nil {
+			ctx.Recorder.log(false, "%v", err)
+		}
+	}()
+
+	f(ctx)
+	ctx.Recorder.Success = true // Success if not panic
+}
@@ -16,7 +16,13 @@ package validator_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/tikv/client-validator/validator"
 )
@@ -169,8 +175,10 @@ func TestValidator(t *testing.T) {
 		},
 	}
 
+	report := validator.Run(validator.Options{Features: []string{"A", "B", "C", "D", "E", "F", "G"}})
+	report.Manifest = nil
 	expectJson, _ := json.Marshal(expect)
-	reportJson, _ := json.Marshal(validator.RunAll())
+	reportJson, _ := json.Marshal(report)
 
 	if !bytes.Equal(expectJson, reportJson) {
 		t.Logf("expect: %s", expectJson)
@@ -178,3 +186,399 @@ func TestValidator(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+var _ = validator.RegisterFeature("R", "describe R", nil, func(ctx validator.ExecContext) validator.FeatureStatus {
+	ctx.Log("%v", ctx.Rand().Int63())
+	return validator.FeaturePass
+})
+
+var _ = validator.RegisterTest("test R", []string{"R"}, func(ctx validator.ExecContext) {
+	ctx.Log("%v", ctx.Rand().Int63())
+})
+
+func TestReplay(t *testing.T) {
+	validator.LogTimeFormat = "[TIME]"
+	validator.LogFileLine = false
+
+	report := validator.Run(validator.Options{Seed: 42, Features: []string{"R"}})
+	if len(report.Features) != 1 || report.Features[0].Key != "R" {
+		t.Fatalf("expect only feature R in report, got %+v", report.Features)
+	}
+	manifest := report.Manifest
+	if manifest.Seed != 42 || len(manifest.Features) != 1 || len(manifest.Tests) != 1 || manifest.Tests[0] != "test R" {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+
+	replay := validator.Run(validator.Options{Seed: manifest.Seed, Features: manifest.Features, Tests: manifest.Tests})
+	replay.Manifest, report.Manifest = nil, nil
+	reportJson, _ := json.Marshal(report)
+	replayJson, _ := json.Marshal(replay)
+	if !bytes.Equal(reportJson, replayJson) {
+		t.Logf("report: %s", reportJson)
+		t.Logf("replay: %s", replayJson)
+		t.FailNow()
+	}
+
+	if err := (validator.Options{Features: manifest.Features, Tests: manifest.Tests}).Validate(); err != nil {
+		t.Fatalf("expect valid options, got %v", err)
+	}
+	if err := (validator.Options{Features: []string{"R", "renamed"}}).Validate(); err == nil {
+		t.Fatalf("expect error for unknown feature")
+	}
+	if err := (validator.Options{Tests: []string{"removed test"}}).Validate(); err == nil {
+		t.Fatalf("expect error for unknown test")
+	}
+
+	other := validator.Run(validator.Options{Seed: 43, Features: []string{"R"}})
+	other.Manifest = nil
+	otherJson, _ := json.Marshal(other)
+	if bytes.Equal(reportJson, otherJson) {
+		t.Fatalf("expect different random values with different seeds")
+	}
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	report := validator.Report{
+		Stories: []validator.StoryReport{
+			{
+				Features: []validator.FeatureReport{
+					{Key: "A", Status: validator.FeaturePass},
+					{Key: "B", Status: validator.FeatureNotImplemented},
+				},
+			},
+		},
+		Features: []validator.FeatureReport{
+			{Key: "C", Status: validator.FeatureFail},
+			{Key: "D", Status: validator.FeaturePass},
+			{Key: "E", Status: validator.FeatureSkip},
+			{Key: "G", Status: validator.FeatureDefect},
+		},
+	}
+	report.CheckCapabilities([]string{"B", "C", "D", "E", "X"})
+
+	expect := []validator.CapabilityMismatch{
+		{Key: "A", Status: validator.FeaturePass},
+		{Key: "B", Status: validator.FeatureNotImplemented, Claimed: true},
+		{Key: "C", Status: validator.FeatureFail, Claimed: true},
+		{Key: "G", Status: validator.FeatureDefect},
+		{Key: "X", Claimed: true},
+	}
+	expectJson, _ := json.Marshal(expect)
+	gotJson, _ := json.Marshal(report.Mismatches)
+	if !bytes.Equal(expectJson, gotJson) {
+		t.Logf("expect: %s", expectJson)
+		t.Logf("got   : %s", gotJson)
+		t.FailNow()
+	}
+}
+
+// lateTracer is created by the checker of T, and used after it returns.
+var lateTracer *validator.Tracer
+
+var _ = validator.RegisterFeature("T", "describe T", nil, func(ctx validator.ExecContext) validator.FeatureStatus {
+	lateTracer = validator.NewTracer()
+	lateTracer.Trace("call %s", "foo")
+	return validator.FeaturePass
+})
+
+var _ = validator.RegisterTest("test T", []string{"T"}, func(ctx validator.ExecContext) {
+	// Dropped since the checker has returned.
+	if lateTracer.Enabled() {
+		ctx.Fail("expect tracing of the checker disabled")
+	}
+	lateTracer.Trace("call %s", "late")
+
+	tracer := validator.NewTracer()
+	done := make(chan struct{})
+	go func() {
+		tracer.Trace("call %s", "bar")
+		close(done)
+	}()
+	<-done
+})
+
+func TestTrace(t *testing.T) {
+	validator.LogTimeFormat = "[TIME]"
+	validator.LogFileLine = false
+
+	// Dropped since no checker or test is running.
+	tracer := validator.NewTracer()
+	if tracer.Enabled() {
+		t.Fatal("expect tracing disabled")
+	}
+	tracer.Trace("call %s", "baz")
+
+	report := validator.Run(validator.Options{Features: []string{"T"}})
+	expect := [][]string{
+		{"[TIME] call foo", "[TIME] check finish. success=true, feature.status=PASS"},
+		{"[TIME] call bar", "[TIME] test finish. success=true, feature.status=PASS"},
+	}
+	records := report.Features[0].Records
+	if len(records) != len(expect) {
+		t.Fatalf("expect %d records, got %+v", len(expect), records)
+	}
+	for i := range expect {
+		expectJson, _ := json.Marshal(expect[i])
+		gotJson, _ := json.Marshal(records[i].Logs)
+		if !bytes.Equal(expectJson, gotJson) {
+			t.Logf("expect: %s", expectJson)
+			t.Logf("got   : %s", gotJson)
+			t.FailNow()
+		}
+	}
+}
+
+var _ = validator.RegisterFeature("S", "describe S", nil, func(_ validator.ExecContext) validator.FeatureStatus {
+	return validator.FeaturePass
+})
+
+var _ = validator.RegisterBenchmark("S.loop", []string{"S"}, func(ctx validator.BenchContext) {
+	tracer := validator.NewTracer()
+	ctx.Loop(func() {
+		if tracer.Enabled() {
+			ctx.Fail("tracing is enabled in Loop")
+		}
+	})
+})
+
+var _ = validator.RegisterBenchmark("S.fail", []string{"S"}, func(ctx validator.BenchContext) {
+	ctx.Loop(func() { ctx.Fail("S has a bug") })
+})
+
+var _ = validator.RegisterBenchmark("S.skip", []string{"S", "C"}, func(ctx validator.BenchContext) {
+	ctx.Loop(func() {})
+})
+
+func TestBenchmark(t *testing.T) {
+	report := validator.Run(validator.Options{
+		Features:   []string{"C", "S"},
+		Bench:      regexp.MustCompile(`^S\.`),
+		BenchTime:  validator.BenchTime{Ops: 100},
+		BenchCount: 2,
+	})
+	if len(report.Benchmarks) != 3 {
+		t.Fatalf("expect 3 benchmarks, got %+v", report.Benchmarks)
+	}
+
+	loop := report.Benchmarks[0]
+	if loop.Key != "S.loop" || loop.Status != validator.FeaturePass || len(loop.Rounds) != 2 || len(loop.Records) != 2 {
+		t.Fatalf("unexpected report %+v", loop)
+	}
+	if loop.Rounds[0].Ops != 100 || loop.Latency.Count != 200 || loop.Throughput <= 0 {
+		t.Fatalf("unexpected report %+v", loop)
+	}
+	if s := report.Benchmarks[1]; s.Key != "S.fail" || s.Status != validator.FeatureFail || len(s.Rounds) != 0 {
+		t.Fatalf("unexpected report %+v", s)
+	}
+	if s := report.Benchmarks[2]; s.Key != "S.skip" || s.Status != validator.FeatureSkip || len(s.Records) != 0 {
+		t.Fatalf("unexpected report %+v", s)
+	}
+
+	report = validator.Run(validator.Options{Features: []string{"S"}})
+	if len(report.Benchmarks) != 0 {
+		t.Fatalf("expect no benchmarks without pattern, got %+v", report.Benchmarks)
+	}
+}
+
+func TestParseBenchTime(t *testing.T) {
+	for s, expect := range map[string]validator.BenchTime{
+		"10s":  {Duration: 10 * time.Second},
+		"100x": {Ops: 100},
+	} {
+		if bt, err := validator.ParseBenchTime(s); err != nil || bt != expect {
+			t.Fatalf("parse %s: expect %v, got %v, %v", s, expect, bt, err)
+		}
+	}
+	for _, s := range []string{"", "x", "0x", "-1s", "10"} {
+		if _, err := validator.ParseBenchTime(s); err == nil {
+			t.Fatalf("parse %s: expect error", s)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	var h validator.Histogram
+	for i := 1; i <= 1000; i++ {
+		h.Add(time.Duration(i) * time.Microsecond)
+	}
+	check := func(q float64, expect time.Duration) {
+		got := h.Percentile(q)
+		if got < expect || float64(got) > float64(expect)*1.1 {
+			t.Fatalf("percentile %v: expect %v, got %v", q, expect, got)
+		}
+	}
+	check(0.5, 500*time.Microsecond)
+	check(0.99, 990*time.Microsecond)
+	check(1, 1000*time.Microsecond)
+	if h.Min != time.Microsecond || h.Max != time.Millisecond || h.Mean() != 500500*time.Nanosecond {
+		t.Fatalf("unexpected histogram %+v", h)
+	}
+
+	var merged validator.Histogram
+	merged.Merge(&h)
+	merged.Merge(&h)
+	if merged.Count != 2000 || merged.Percentile(0.5) != h.Percentile(0.5) || len(merged.Buckets) != len(h.Buckets) {
+		t.Fatalf("unexpected merged histogram %+v", merged)
+	}
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	check := func(x, y []float64, expect float64) {
+		if p := validator.MannWhitneyU(x, y); math.Abs(p-expect) > 1e-3 {
+			t.Fatalf("U test of %v and %v: expect p=%v, got %v", x, y, expect, p)
+		}
+	}
+	check([]float64{1, 2, 3, 4}, []float64{5, 6, 7, 8}, 2.0/70)
+	check([]float64{5, 6, 7, 8}, []float64{1, 2, 3, 4}, 2.0/70)
+	check([]float64{1, 3, 5, 7}, []float64{2, 4, 6, 8}, 0.686)
+	check([]float64{1}, []float64{2}, 1)
+	check([]float64{1, 1, 1}, []float64{1, 1, 1}, 1)
+	// Normal approximation with ties.
+	check([]float64{1, 2, 2, 3, 4, 5}, []float64{6, 7, 7, 8, 9, 10}, 0.005)
+}
+
+func TestCompareBenchmarks(t *testing.T) {
+	rounds := func(throughput float64, p99 time.Duration) []validator.BenchRound {
+		var rs []validator.BenchRound
+		for i := 0; i < 5; i++ {
+			rs = append(rs, validator.BenchRound{
+				Throughput: throughput + float64(i),
+				P50:        time.Millisecond + time.Duration(i),
+				P99:        p99 + time.Duration(i),
+			})
+		}
+		return rs
+	}
+	old := []validator.BenchReport{
+		{Key: "X", Rounds: rounds(1000, 10*time.Millisecond)},
+		{Key: "Y", Rounds: rounds(1000, 10*time.Millisecond)},
+		{Key: "Z", Rounds: rounds(1000, 10*time.Millisecond)},
+		{Key: "V", Rounds: rounds(1000, 10*time.Millisecond)},
+		{Key: "U"},
+	}
+	new := []validator.BenchReport{
+		{Key: "X", Rounds: rounds(800, 10*time.Millisecond)},  // throughput regression
+		{Key: "Y", Rounds: rounds(1010, 20*time.Millisecond)}, // p99 regression, throughput change below threshold
+		{Key: "W", Rounds: rounds(1000, 10*time.Millisecond)}, // not in old
+		{Key: "V", Status: validator.FeatureFail},             // all rounds failed
+		{Key: "U", Rounds: rounds(1000, 10*time.Millisecond)}, // no rounds in old
+		// Z is missing in new
+	}
+	comparisons := validator.CompareBenchmarks(old, new, validator.CompareOptions{Threshold: 0.05})
+	var regressions []string
+	for _, c := range comparisons {
+		if c.Regression {
+			regressions = append(regressions, c.Key+"."+c.Metric)
+		}
+	}
+	expect := []string{"X.throughput", "Y.p99",
+		"Z.throughput", "Z.p50", "Z.p99",
+		"V.throughput", "V.p50", "V.p99"}
+	if len(comparisons) != 12 || strings.Join(regressions, ",") != strings.Join(expect, ",") {
+		t.Fatalf("expect regressions %v, got %+v", expect, comparisons)
+	}
+}
+
+var _ = validator.RegisterSoak("S.soak", []string{"S"}, func(ctx validator.SoakContext) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Millisecond):
+					ctx.Record("op", time.Millisecond, nil)
+				}
+			}
+		}()
+	}
+	ctx.Event("fault")
+	ctx.Record("op", time.Millisecond, errors.New("error"))
+	ctx.Record("op", time.Millisecond, errors.New("error"))
+	wg.Wait()
+})
+
+var _ = validator.RegisterSoak("S.anomaly", []string{"S"}, func(ctx validator.SoakContext) {
+	ctx.Anomaly("lost %s", "k")
+	<-ctx.Done()
+})
+
+func TestSoak(t *testing.T) {
+	report := validator.Run(validator.Options{
+		Features:            []string{"S"},
+		Soak:                regexp.MustCompile(`^S\.`),
+		SoakDuration:        50 * time.Millisecond,
+		SoakWindow:          20 * time.Millisecond,
+		SoakCheckGoroutines: true,
+	})
+	if len(report.Soaks) != 2 {
+		t.Fatalf("expect 2 soaks, got %+v", report.Soaks)
+	}
+
+	soak := report.Soaks[0]
+	if soak.Key != "S.soak" || soak.Status != validator.FeaturePass || len(soak.Events) != 1 || len(soak.Anomalies) != 0 {
+		t.Fatalf("unexpected report %+v", soak)
+	}
+	if len(soak.Timeline) < 3 {
+		t.Fatalf("expect 3 windows at least, got %+v", soak.Timeline)
+	}
+	var ops uint64
+	for _, w := range soak.Timeline {
+		ops += w.Ops["op"]
+		if w.Goroutines == 0 {
+			t.Fatalf("expect goroutines counted, got %+v", w)
+		}
+	}
+	first := soak.Timeline[0]
+	if ops == 0 || first.Errors["op"] != 2 || len(first.ErrorSamples) != 1 || first.ErrorSamples[0] != "op: error" {
+		t.Fatalf("unexpected timeline %+v", soak.Timeline)
+	}
+
+	anomaly := report.Soaks[1]
+	if anomaly.Status != validator.FeatureFail || len(anomaly.Anomalies) != 1 || anomaly.Anomalies[0].Message != "lost k" {
+		t.Fatalf("unexpected report %+v", anomaly)
+	}
+}
+
+// leak starts a goroutine every millisecond, which exits only when the soak
+// finishes.
+var _ = validator.RegisterSoak("leak", []string{"S"}, func(ctx validator.SoakContext) {
+	release := make(chan struct{})
+	defer close(release)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Millisecond):
+			go func() { <-release }()
+		}
+	}
+})
+
+func TestSoakGoroutineLeak(t *testing.T) {
+	for _, check := range []bool{true, false} {
+		report := validator.Run(validator.Options{
+			Features:            []string{"S"},
+			Soak:                regexp.MustCompile(`^leak$`),
+			SoakDuration:        200 * time.Millisecond,
+			SoakWindow:          10 * time.Millisecond,
+			SoakCheckGoroutines: check,
+		})
+		if len(report.Soaks) != 1 {
+			t.Fatalf("expect 1 soak, got %+v", report.Soaks)
+		}
+		soak := report.Soaks[0]
+		if !check {
+			if soak.Status != validator.FeaturePass || len(soak.Anomalies) != 0 {
+				t.Fatalf("expect no leak reported without checking goroutines, got %+v", soak)
+			}
+			continue
+		}
+		if soak.Status != validator.FeatureFail || len(soak.Anomalies) != 1 || !strings.HasPrefix(soak.Anomalies[0].Message, "goroutines grow") {
+			t.Fatalf("unexpected report %+v", soak)
+		}
+	}
+}